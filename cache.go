@@ -0,0 +1,100 @@
+package racket
+
+import (
+	"container/list"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// resultCache is a goro-safe LRU cache of successful Work values, with an optional TTL.
+type resultCache struct {
+	lock    sync.Mutex
+	size    int
+	ttl     time.Duration
+	keyFunc func(Work) (string, bool)
+	order   *list.List // of *cacheEntry, most recently used at the front
+	entries map[string]*list.Element
+}
+
+// cacheEntry is a cached value, and when it expires.
+type cacheEntry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+// newResultCache returns a resultCache holding up to size values, each for up to ttl (if greater than 0),
+// keyed by the keyFunc, or by Work equality if it is nil.
+func newResultCache(size int, ttl time.Duration, keyFunc func(Work) string) *resultCache {
+	c := &resultCache{
+		size:    max(size, 1),
+		ttl:     ttl,
+		keyFunc: workKey,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+	if keyFunc != nil {
+		c.keyFunc = func(w Work) (string, bool) {
+			return keyFunc(w), true
+		}
+	}
+	return c
+}
+
+// workKey returns a key that is equal for equal Work, and false if the Work can't be keyed.
+func workKey(w Work) (string, bool) {
+	b, err := json.Marshal(w.config) // maps marshal with sorted keys
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
+
+// get returns the cached value for the Work, and true, if there is one that hasn't expired.
+func (c *resultCache) get(w Work) (any, bool) {
+	key, ok := c.keyFunc(w)
+	if !ok {
+		return nil, false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*cacheEntry)
+	if c.ttl > 0 && time.Now().After(entry.expires) {
+		c.order.Remove(e)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return entry.value, true
+}
+
+// put caches the value for the Work, evicting the least recently used value if the cache is full.
+func (c *resultCache) put(w Work, value any) {
+	key, ok := c.keyFunc(w)
+	if !ok {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry := &cacheEntry{key: key, value: value, expires: time.Now().Add(c.ttl)}
+	if e, ok := c.entries[key]; ok {
+		e.Value = entry
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
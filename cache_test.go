@@ -0,0 +1,98 @@
+package racket
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ResultCache(t *testing.T) {
+
+	Convey("When a resultCache is full, the least recently used value is evicted", t, func() {
+		c := newResultCache(2, 0, nil)
+		a, b, d := NewWork(map[string]any{"k": "a"}), NewWork(map[string]any{"k": "b"}), NewWork(map[string]any{"k": "d"})
+		c.put(a, 1)
+		c.put(b, 2)
+		_, ok := c.get(a) // a is now more recent than b
+		So(ok, ShouldBeTrue)
+		c.put(d, 3)
+
+		_, ok = c.get(b)
+		So(ok, ShouldBeFalse)
+		v, ok := c.get(a)
+		So(ok, ShouldBeTrue)
+		So(v, ShouldEqual, 1)
+		v, ok = c.get(d)
+		So(ok, ShouldBeTrue)
+		So(v, ShouldEqual, 3)
+	})
+
+	Convey("When a resultCache has a keyFunc, Work is keyed by it", t, func() {
+		c := newResultCache(2, 0, func(w Work) string { return w.GetString("id") })
+		c.put(NewWork(map[string]any{"id": "1", "noise": 1}), "one")
+		v, ok := c.get(NewWork(map[string]any{"id": "1", "noise": 2}))
+		So(ok, ShouldBeTrue)
+		So(v, ShouldEqual, "one")
+	})
+}
+
+func Test_JobResultCache(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	// run sends the items through a single-worker Job with the cache, returning the ProgressSkipped reasons sent.
+	run := func(j Job, items ...Work) []string {
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+
+		var reasons []string
+		consumed := make(chan struct{})
+		go func() {
+			defer close(consumed)
+			for p := range pchan {
+				if p.Type == ProgressSkipped {
+					reasons = append(reasons, p.Data.(string))
+				}
+			}
+		}()
+
+		for _, w := range items {
+			wchan <- w
+		}
+		done()
+		<-j.IsDone()
+
+		close(pchan)
+		<-consumed
+		return reasons
+	}
+
+	Convey("When identical Work is repeated, a cache hit skips running it", t, func() {
+		var runs atomic.Int64
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			runs.Add(1)
+		}, WithResultCache(10, time.Minute, nil))
+
+		reasons := run(j, NewWork(map[string]any{"n": 1}), NewWork(map[string]any{"n": 1}), NewWork(map[string]any{"n": 2}))
+		So(runs.Load(), ShouldEqual, 2)
+		So(reasons, ShouldResemble, []string{SkipCacheHit})
+		So(j.CompletedCount(), ShouldEqual, 3)
+		So(j.Completed(), ShouldEqual, 3) // a cache hit counts as finished, and successful
+	})
+
+	Convey("When the cached value has expired, the Work is run again", t, func() {
+		var runs atomic.Int64
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			runs.Add(1)
+			if work.GetBool("slow") {
+				time.Sleep(30 * time.Millisecond) // outlive the TTL
+			}
+		}, WithResultCache(10, 10*time.Millisecond, nil))
+
+		reasons := run(j, NewWork(map[string]any{"n": 1}), NewWork(map[string]any{"slow": true}), NewWork(map[string]any{"n": 1}))
+		So(runs.Load(), ShouldEqual, 3)
+		So(reasons, ShouldBeEmpty)
+	})
+}
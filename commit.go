@@ -0,0 +1,74 @@
+package racket
+
+import (
+	"context"
+	"sync"
+)
+
+// NewCommitJob returns a Job that processes Work in two phases: compute is called concurrently, as with any Job,
+// and then commit is called with the value of each successful compute, in strict submission order, by a single
+// goroutine (e.g. for appending to an ordered log). The seq is the index (from 0) of the Work in the order it was
+// sent to the work channel; Work that failed or was skipped is not committed, but doesn't hold up the Work behind
+// it. IsDone waits for the commits. It panics if compute or commit are nil, rather than at the first dispatch.
+func NewCommitJob[T any](compute func(id any, work Work) (T, error), commit func(seq int, value T), opts ...JobOption) Job {
+	if compute == nil || commit == nil {
+		panic("racket: NewCommitJob called with a nil compute or commit func")
+	}
+
+	j := newJob(func(_ context.Context, id any, work Work, _ chan<- Progress) (any, error) {
+		return compute(id, work)
+	}, opts...)
+	j.commitFunc = func(seq int, value any) {
+		v, _ := value.(T) // a nil value of an interface T isn't a T
+		commit(seq, v)
+	}
+	return j
+}
+
+// sequenced is a Result, and the seq of its Work.
+type sequenced struct {
+	seq    int
+	result Result
+}
+
+// committer reorders Results into submission order, and commits the successful ones.
+type committer struct {
+	commit    func(seq int, value any)
+	recvLock  sync.Mutex
+	received  int // guarded by recvLock
+	in        chan sequenced
+	committed chan struct{}
+}
+
+// newCommitter returns a committer for the commit func.
+func newCommitter(commit func(seq int, value any)) *committer {
+	return &committer{
+		commit:    commit,
+		in:        make(chan sequenced),
+		committed: make(chan struct{}),
+	}
+}
+
+// run commits the Results from in, in seq order, until in is closed, then closes committed.
+func (c *committer) run() {
+	defer close(c.committed)
+
+	var (
+		next    int
+		pending = make(map[int]Result)
+	)
+	for s := range c.in {
+		pending[s.seq] = s.result
+		for {
+			r, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			if r.Status == ResultSuccess {
+				c.commit(next, r.Value)
+			}
+			next++
+		}
+	}
+}
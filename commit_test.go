@@ -0,0 +1,101 @@
+package racket
+
+import (
+	"errors"
+	"io"
+	"log"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_CommitJob(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 20
+	maxWorkers := 5
+
+	Convey("When a CommitJob computes in parallel, the commits are still strictly in submission order.", t, func() {
+		var (
+			active    atomic.Int64
+			maxActive atomic.Int64
+			seqs      []int // only the committer touches these, and IsDone is a barrier
+			values    []int
+		)
+
+		j := NewCommitJob(func(id any, work Work) (int, error) {
+			n := active.Add(1)
+			defer active.Add(-1)
+			for {
+				m := maxActive.Load()
+				if n <= m || maxActive.CompareAndSwap(m, n) {
+					break
+				}
+			}
+
+			i := work.GetInt("n")
+			// Later Work finishes sooner
+			time.Sleep(time.Duration(its-i) * time.Millisecond)
+			if i == 7 {
+				return 0, errors.New("unlucky")
+			}
+			return i * 10, nil
+		}, func(seq int, value int) {
+			seqs = append(seqs, seq)
+			values = append(values, value)
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(maxWorkers, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for i := range its {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+		done()
+		<-j.IsDone()
+
+		So(maxActive.Load(), ShouldBeGreaterThan, 1)
+
+		var (
+			expectedSeqs   []int
+			expectedValues []int
+		)
+		for i := range its {
+			if i == 7 {
+				continue // failed, so not committed
+			}
+			expectedSeqs = append(expectedSeqs, i)
+			expectedValues = append(expectedValues, i*10)
+		}
+		So(seqs, ShouldResemble, expectedSeqs)
+		So(values, ShouldResemble, expectedValues)
+	})
+
+	Convey("When a CommitJob of an interface type computes a nil value, it is committed as nil.", t, func() {
+		var values []error
+		j := NewCommitJob(func(id any, work Work) (error, error) {
+			return nil, nil
+		}, func(seq int, value error) {
+			values = append(values, value)
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(nil)
+		done()
+		<-j.IsDone()
+		So(values, ShouldResemble, []error{nil})
+	})
+
+	Convey("When a CommitJob is created with a nil func, it panics.", t, func() {
+		So(func() { NewCommitJob[int](nil, func(int, int) {}) }, ShouldPanic)
+		So(func() { NewCommitJob(func(any, Work) (int, error) { return 0, nil }, nil) }, ShouldPanic)
+	})
+}
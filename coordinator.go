@@ -0,0 +1,72 @@
+package racket
+
+import (
+	"sync/atomic"
+
+	"github.com/cognusion/semaphore"
+)
+
+// JobCoordinator caps how many Jobs run concurrently, much as a Job caps its workers: Jobs beyond the
+// cap wait their turn to be admitted.
+type JobCoordinator struct {
+	lock    semaphore.Semaphore
+	running atomic.Int64
+}
+
+// NewJobCoordinator returns a JobCoordinator that admits up to maxJobs Jobs at once.
+// Passing a maxJobs less than 1 will panic.
+func NewJobCoordinator(maxJobs int) *JobCoordinator {
+	if maxJobs < 1 {
+		panic("racket: NewJobCoordinator called with a maxJobs less than 1")
+	}
+
+	return &JobCoordinator{
+		lock: semaphore.NewSemaphore(maxJobs),
+	}
+}
+
+// Run blocks until the Job can be admitted, and then hires its Supervisor with the maxWorkers and workChan,
+// returning the same progressChan and doneFunc. The Job holds its place until IsDone fires. If the Supervisor
+// panics, e.g. because the Job was already Supervised, the place is given up before the panic carries on.
+func (c *JobCoordinator) Run(job Job, maxWorkers int, workChan chan Work) (progressChan chan Progress, doneFunc func()) {
+	c.lock.Lock()
+	c.running.Add(1)
+
+	supervised := false
+	defer func() {
+		if !supervised {
+			c.running.Add(-1)
+			c.lock.Unlock()
+		}
+	}()
+	progressChan, doneFunc = job.Supervisor(maxWorkers, workChan)
+	supervised = true
+
+	go func() {
+		<-job.IsDone()
+		c.running.Add(-1)
+		c.lock.Unlock()
+	}()
+
+	return progressChan, doneFunc
+}
+
+// Running returns the number of Jobs currently admitted.
+func (c *JobCoordinator) Running() int {
+	return int(c.running.Load())
+}
+
+// AllDone returns a channel that fires, as IsDone does, once every one of the Jobs IsDone. With no Jobs, it fires
+// immediately.
+func AllDone(jobs ...Job) <-chan bool {
+	b := make(chan bool, 1) // so we don't linger if the caller stops waiting
+
+	go func() {
+		for _, job := range jobs {
+			<-job.IsDone()
+		}
+		b <- true
+	}()
+
+	return b
+}
@@ -0,0 +1,136 @@
+package racket
+
+import (
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_JobCoordinator(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	maxJobs := 2
+	jobs := 6
+
+	Convey("When more Jobs than the cap are Run through a JobCoordinator, the cap holds.", t, func(c C) {
+		var (
+			active    atomic.Int64
+			maxActive atomic.Int64
+			completed atomic.Int64
+			wg        sync.WaitGroup
+		)
+
+		jc := NewJobCoordinator(maxJobs)
+		for range jobs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+					n := active.Add(1)
+					for {
+						m := maxActive.Load()
+						if n <= m || maxActive.CompareAndSwap(m, n) {
+							break
+						}
+					}
+					time.Sleep(20 * time.Millisecond)
+					active.Add(-1)
+					completed.Add(1)
+				})
+				wchan := make(chan Work)
+				pchan, done := jc.Run(j, 1, wchan)
+				defer close(pchan)
+				go ProgressLogger(disco, false, nil, pchan, nil)
+
+				c.So(jc.Running(), ShouldBeBetweenOrEqual, 1, maxJobs)
+				wchan <- NewWork(nil)
+				done()
+				<-j.IsDone()
+			}()
+		}
+		wg.Wait()
+
+		c.So(completed.Load(), ShouldEqual, jobs)
+		c.So(maxActive.Load(), ShouldBeBetweenOrEqual, 1, maxJobs)
+	})
+
+	Convey("When a JobCoordinator is created with a nonsensical cap, it panics.", t, func() {
+		So(func() { NewJobCoordinator(0) }, ShouldPanic)
+	})
+	Convey("When a Job's Supervisor panics in a JobCoordinator, its place is given up.", t, func() {
+		jc := NewJobCoordinator(1)
+		So(func() { jc.Run(NewJob(func(id any, work Work, pchan chan<- Progress) {}), 0, make(chan Work)) }, ShouldPanic)
+		So(jc.Running(), ShouldEqual, 0)
+
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {})
+		pchan, done := jc.Run(j, 1, make(chan Work)) // would block forever if the place were still held
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+		done()
+		<-j.IsDone()
+	})
+}
+
+func Test_AllDone(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	Convey("When two Jobs finish at different times, AllDone fires only after both.", t, func() {
+		start := func(delay time.Duration) (Job, func()) {
+			j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+				time.Sleep(delay)
+			})
+			wchan := make(chan Work)
+			pchan, done := j.Supervisor(1, wchan)
+			go ProgressLogger(disco, false, nil, pchan, nil)
+			wchan <- NewWork(nil)
+			done()
+			return j, func() { close(pchan) }
+		}
+
+		fast, closeFast := start(10 * time.Millisecond)
+		defer closeFast()
+		slow, closeSlow := start(200 * time.Millisecond)
+		defer closeSlow()
+
+		allDone := AllDone(fast, slow)
+		<-fast.IsDone()
+		select {
+		case <-allDone:
+			So("AllDone fired before the slow Job was done", ShouldBeEmpty)
+		default:
+		}
+
+		<-allDone
+		So(slow.CompletedCount(), ShouldEqual, 1)
+		So(fast.CompletedCount(), ShouldEqual, 1)
+	})
+
+	Convey("When there are no Jobs, AllDone fires immediately.", t, func() {
+		select {
+		case b := <-AllDone():
+			So(b, ShouldBeTrue)
+		case <-time.After(time.Second):
+			So("AllDone with no Jobs didn't fire", ShouldBeEmpty)
+		}
+	})
+	Convey("When the caller stops waiting on AllDone, it doesn't linger once the Jobs are done.", t, func() {
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {})
+		pchan, done := j.Supervisor(1, make(chan Work))
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		_ = AllDone(j) // abandoned
+		done()
+		<-j.IsDone()
+	})
+}
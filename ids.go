@@ -0,0 +1,19 @@
+package racket
+
+import "sync/atomic"
+
+// IDAllocator generates the IDs given to workers, see WithIDAllocator.
+type IDAllocator interface {
+	// Next returns the ID for the next worker.
+	Next() any
+}
+
+// SequentialIDs is an IDAllocator of incrementing ints, starting at 1.
+type SequentialIDs struct {
+	last atomic.Int64
+}
+
+// Next returns the next int ID.
+func (s *SequentialIDs) Next() any {
+	return int(s.last.Add(1))
+}
@@ -0,0 +1,48 @@
+package racket
+
+// InlineJob is a Job-alike that runs its WorkerFunc synchronously on the calling goroutine, one unit of Work
+// at a time. There is no Supervisor, no work channel and no concurrency, which makes testing WorkerFunc logic
+// deterministic and debuggable.
+type InlineJob struct {
+	workerFunc WorkerFunc
+	consumer   func(Progress)
+	count      int
+}
+
+// NewInlineJob consumes a WorkerFunc to accomplish Work, and an optional consumer to hand Progress to,
+// and returns an InlineJob. It panics if workerFunc is nil.
+func NewInlineJob(workerFunc WorkerFunc, consumer func(Progress)) *InlineJob {
+	if workerFunc == nil {
+		panic("racket: NewInlineJob called with a nil WorkerFunc")
+	}
+	return &InlineJob{
+		workerFunc: workerFunc,
+		consumer:   consumer,
+	}
+}
+
+// Add runs the WorkerFunc against the Work on the calling goroutine, with an ID counting up from 0. It returns once
+// the WorkerFunc has returned and every Progress it sent has been handed to the consumer, in the order it was sent.
+// A short-lived goroutine relays Progress to the consumer, so the WorkerFunc may send freely; it is finished with
+// before Add returns, even if the WorkerFunc panics, in which case the panic is passed on.
+func (j *InlineJob) Add(work Work) {
+	id := j.count
+	j.count++
+
+	pchan := make(chan Progress)
+	flushed := make(chan struct{})
+	go func() {
+		defer close(flushed)
+		for p := range pchan {
+			if j.consumer != nil {
+				j.consumer(p)
+			}
+		}
+	}()
+	defer func() {
+		close(pchan)
+		<-flushed
+	}()
+
+	j.workerFunc(id, work, pchan)
+}
@@ -0,0 +1,62 @@
+package racket
+
+import (
+	"testing"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_InlineJob(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When an InlineJob has Work added, it is run synchronously and in order.", t, func() {
+		var (
+			worked   []int
+			progress []string
+		)
+
+		wf := func(id any, work Work, pchan chan<- Progress) {
+			worked = append(worked, work.GetInt("n"))
+			pchan <- PMessagef("%d from %v", work.GetInt("n"), id)
+		}
+
+		j := NewInlineJob(wf, func(p Progress) {
+			progress = append(progress, p.Data.(string))
+		})
+
+		for i := range 5 {
+			j.Add(NewWork(map[string]any{"n": i}))
+			// Everything has happened by the time Add returns
+			So(worked, ShouldHaveLength, i+1)
+			So(progress, ShouldHaveLength, i+1)
+		}
+
+		So(worked, ShouldResemble, []int{0, 1, 2, 3, 4})
+		So(progress, ShouldResemble, []string{"0 from 0", "1 from 1", "2 from 2", "3 from 3", "4 from 4"})
+	})
+
+	Convey("When an InlineJob has no consumer, Progress is discarded.", t, func() {
+		var count int
+		j := NewInlineJob(func(id any, work Work, pchan chan<- Progress) {
+			pchan <- PMessagef("ignored")
+			count++
+		}, nil)
+
+		j.Add(NewWork(nil))
+		So(count, ShouldEqual, 1)
+	})
+
+	Convey("When an InlineJob's WorkerFunc panics, the panic is passed on, after the Progress it sent.", t, func() {
+		var progress []string
+		j := NewInlineJob(func(id any, work Work, pchan chan<- Progress) {
+			pchan <- PMessagef("before")
+			panic("boom")
+		}, func(p Progress) {
+			progress = append(progress, p.Data.(string))
+		})
+
+		So(func() { j.Add(NewWork(nil)) }, ShouldPanicWith, "boom")
+		So(progress, ShouldResemble, []string{"before"})
+	})
+}
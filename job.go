@@ -16,20 +16,55 @@
 package racket
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"runtime/debug"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/cognusion/semaphore"
 )
 
+// ErrJobRunning is returned when an operation requires the Job to not be running, and it is.
+var ErrJobRunning = errors.New("racket: Job is still running")
+
+// alreadySupervised is the panic when a Job is Supervised again before it is Reset.
+const alreadySupervised = "racket: Supervisor already running; Reset the Job once it is done to Supervise it again"
+
+var (
+	// progressWatchInterval is how often a buffered progress channel is sampled for fullness.
+	progressWatchInterval = 10 * time.Millisecond
+	// progressWarnInterval is the minimum time between progress channel fullness warnings.
+	progressWarnInterval = 5 * time.Second
+	// defaultCleanupTimeout is how long Shutdown waits for registered cleanups, unless WithCleanupTimeout is set.
+	defaultCleanupTimeout = 10 * time.Second
+)
+
 // Job is a repetitive task that uses a common Supervisor to ensure Work is properly distributed,
 // that the correct number of workers are available to do the Work, and that those workers can
 // send Progress along as-needed.
 type Job interface {
 	// Supervisor will ensure there are workers to do the Work, and a channel to receive that Work on,
 	// while also supplying a means to receive progress reports and how to report back when there is no
-	// more work to do.
+	// more work to do. With a maxWorkers of 1, Work is processed strictly in the order it was sent (FIFO).
+	// A maxWorkers less than 1 panics, as does Supervising (by any of the Supervisor variants) a Job again before it
+	// is Reset, rather than clobbering the running Supervisor.
 	Supervisor(maxWorkers int, workChan chan Work) (progressChan chan Progress, doneFunc func())
+	// SupervisorContext is Supervisor, but when the ctx is done, the Job is cancelled: no more workers are spawned,
+	// the Job's context is cancelled, and done is signaled, so IsDone still resolves once the workers have left.
+	SupervisorContext(ctx context.Context, maxWorkers int, workChan chan Work) (progressChan chan Progress, doneFunc func())
+	// SupervisorPriority is Supervisor, but Work is submitted as PriorityWork, and the workers pull the highest
+	// Priority Work first. The doneFunc signals that no more Work will be submitted; queued Work is still done.
+	SupervisorPriority(maxWorkers int) (submit func(PriorityWork), progressChan chan Progress, doneFunc func())
+	// Resize changes the maximum number of concurrent workers of a running Job. Growing takes effect immediately,
+	// while shrinking lets busy workers finish their current Work, spawning no more until the Job is under the new
+	// maximum. It has no effect with WithPooledWorkers, or before the Job is Supervised. A maxWorkers less than 1
+	// panics.
+	Resize(maxWorkers int)
 	// NewWorker will ready a worker to do some Work, giving it an ID to reference it by. Calling this directly
 	// is generally unnecessary as Supervisor will handle it.
 	NewWorker(id any)
@@ -37,65 +72,917 @@ type Job interface {
 	// It's flexible enough to be used as a blocking inline "wait" or in a select{} so other things can occur whilst
 	// waiting.
 	IsDone() <-chan bool
+	// IsDoneTimeout is IsDone, but bounded: the returned channel receives exactly one value, true if the Job was
+	// done within the timeout, or false if it wasn't.
+	IsDoneTimeout(timeout time.Duration) <-chan bool
+	// Err returns the error that aborted the Job, if any (e.g. a worker panic under PanicAbort).
+	Err() error
+	// Errors returns the errors from all of the Work that failed (after any retries), e.g. as returned by a
+	// WorkerFuncErr, in the order they failed. It is complete once IsDone fires.
+	Errors() []error
+	// DoneAllowRetries signals that there is no more Work, like the doneFunc returned by Supervisor, but
+	// in-flight Work may complete its remaining retry attempts, where the doneFunc stops them after the
+	// current attempt.
+	DoneAllowRetries()
+	// Reset prepares a finished Job to be Supervised again, returning ErrJobRunning if it is still running.
+	Reset() error
+	// CompletedCount returns how many units of Work the workers have finished (whatever the outcome), counted as
+	// each worker returns rather than from any Progress it sent. Skipped Work doesn't count, except for a cache hit
+	// (see WithResultCache), which is finished with its cached value.
+	CompletedCount() int64
+	// Completed returns how many units of Work have completed successfully, without error or cancellation,
+	// e.g. as the numerator of a progress percentage. Unlike CompletedCount, failed Work doesn't count.
+	Completed() int64
+	// Stats returns a snapshot of how the Job is doing. It is safe to call while the Job runs.
+	Stats() JobStats
+	// Drain signals that no more Work will be sent, like the doneFunc returned by Supervisor, but Work already queued
+	// on a buffered work channel (or the SupervisorPriority queue) is still done, as is in-flight Work. Once the
+	// queue is empty, done is signaled, so IsDone fires when the last of it completes. It returns immediately; wait
+	// on IsDone. Work sent after Drain may never be received. See Shutdown to drop the queued Work instead.
+	Drain()
+	// Shutdown cancels the Job, signals done, and waits for the workers to leave. If WithSummary was set, a Summary
+	// is then sent as the final Progress, so the consumer should keep consuming until Shutdown returns, and then
+	// may close the progress channel.
+	Shutdown()
+	// RegisterCleanup registers a func for Shutdown to run once the workers have left, e.g. for a worker to release
+	// an external resource it holds. Shutdown waits for the cleanups, up to a timeout (see WithCleanupTimeout).
+	RegisterCleanup(cleanup func())
+}
+
+// JobDiagnostics is the read-only diagnostics of a Job, for debugging and monitoring. Every Job made by this package
+// has them, so type-assert a Job to JobDiagnostics to reach them.
+type JobDiagnostics interface {
+	// CompletionLog returns the Work completed so far, in order of completion, if WithCompletionLog was set.
+	CompletionLog() []CompletionEntry
+	// LongestRunningWorker returns the ID of the worker that has been working on its current Work the longest,
+	// and for how long, or nil and 0 if no worker is busy.
+	LongestRunningWorker() (id any, dur time.Duration)
+	// ActiveWorkers returns a snapshot of the busy workers, by ID, with the Work each is on, and since when.
+	ActiveWorkers() map[any]WorkerInfo
+	// ResultsDropped returns how many Results have been dropped because the WithResultBuffer buffer was full.
+	ResultsDropped() int64
+}
+
+// CompletionEntry is a record of a worker completing a unit of Work, for debugging scheduling.
+type CompletionEntry struct {
+	ID    any
+	Work  Work
+	Start time.Time
+	End   time.Time
+}
+
+// JobStats is a snapshot of how a Job is doing, see Stats.
+type JobStats struct {
+	// ActiveWorkers is how many workers are spawned, whether busy with Work or waiting for it.
+	ActiveWorkers int64
+	// BusyWorkers is how many of those are busy with Work.
+	BusyWorkers int64
+	// CompletedCount is how many units of Work the workers have finished, as CompletedCount.
+	CompletedCount int64
+	// Done is true once done has been signaled, though workers may still be finishing (see IsDone).
+	Done bool
+}
+
+// WorkerInfo is what a busy worker is working on, and since when, see ActiveWorkers.
+type WorkerInfo struct {
+	Work  Work
+	Start time.Time
 }
 
 // WorkerFunc is a definition for how to accomplish Work!
-// Each invocation can assume it has been giving a unique ID, has it's own unique Work, and it can send
-// various Progress updates over the supplied channel.
+// Each invocation can assume it has been giving an ID unique among the running workers, has it's own unique Work,
+// and it can send various Progress updates over the supplied channel. When spawned by a Supervisor, the ID is the
+// int index (0 to maxWorkers-1) of the concurrency slot the worker occupies, so IDs are reused as slots free up,
+// unless WithIDAllocator was set.
 type WorkerFunc func(id any, work Work, progressChan chan<- Progress)
 
+// WorkerFuncContext is a WorkerFunc that is also handed the Job's shared context, so cooperative
+// workers can notice when the Job has been cancelled (e.g. via StopAfterSuccesses) and bail early.
+// Under SupervisorContext, the shared context is derived from the one given, so it carries its values,
+// and is cancelled with it.
+type WorkerFuncContext func(ctx context.Context, id any, work Work, progressChan chan<- Progress)
+
+// WorkerFuncErr is a definition for how to accomplish Work that may fail! A returned error is sent as a
+// ProgressError, after any retries (see WithRetry) are exhausted.
+type WorkerFuncErr func(id any, work Work) error
+
+// WorkerFuncAttempt is a WorkerFuncErr that is also handed the current attempt number (1-based), so it can
+// behave differently when retried (see WithRetry), e.g. by using a fallback endpoint.
+type WorkerFuncAttempt func(id any, work Work, attempt int) error
+
+// WorkerFuncValue is a WorkerFuncContext that also returns a value for the Result (see WithResults), and an
+// error, which is sent as a ProgressError (and retried, per WithRetry).
+type WorkerFuncValue func(ctx context.Context, id any, work Work, progressChan chan<- Progress) (any, error)
+
+// attemptKey is the context key for the current attempt number, set by process for each attempt.
+type attemptKey struct{}
+
+// workFunc is the internal form that all of the WorkerFunc variants are adapted to.
+type workFunc func(ctx context.Context, id any, work Work, progressChan chan<- Progress) (any, error)
+
 // defaultJob is a Job that takes a dynamic worker definition to accomplish varied Work using the same
 // Supervisor system.
 type defaultJob struct {
-	workerFunc   WorkerFunc
-	workChan     chan Work
-	workerCount  atomic.Int64
-	progressChan chan Progress
-	doneChan     chan struct{}
-	lock         semaphore.Semaphore
+	workerFunc    workFunc
+	workChan      chan Work
+	workerCount   atomic.Int64
+	progressChan  chan Progress
+	doneChan      chan struct{}
+	doneOnce      sync.Once
+	noRetries     chan struct{}
+	noRetriesOnce sync.Once
+	drainChan     chan struct{}
+	drainOnce     sync.Once
+	lock          *semaphore.Semaphore
+	slots         chan int
+	sizeLock      sync.Mutex // guards lock, slots, maxWorkers and retiring, for Resize
+	maxWorkers    int
+	retiring      map[int]struct{}
+	resized       chan struct{}
+	ctx           context.Context
+	cancel        context.CancelFunc
+	successes     atomic.Int64
+	completed     atomic.Int64
+	wg            sync.WaitGroup
+	calls         sync.WaitGroup // WithItemTimeout invocations, which may outlive their worker
+	finished      chan struct{}
+	logLock       sync.Mutex
+	log           []CompletionEntry
+	errLock       sync.Mutex
+	err           error
+	errs          []error
+
+	// options
+	stopAfter      int64
+	defaults       Work
+	completionLog  bool
+	progressBuffer int
+	logger         *log.Logger
+	panicPolicy    PanicPolicy
+	panicStack     bool
+	itemTimeout    time.Duration
+	retryAttempts  int
+	retryBackoff   func(attempt int) time.Duration
+	results        chan<- Result
+	workerRates    *bucketSet
+	rate           *tokenBucket
+	retryBudget    *tokenBucket
+	pooled         bool
+	ids            IDAllocator
+	progressBound  int
+	progressPolicy OverflowPolicy
+	bufferProgress bool
+	relayed        chan struct{}
+	warnAfter      time.Duration
+	activeLock     sync.Mutex
+	active         map[any]WorkerInfo
+	tracer         Tracer
+	propagator     TracePropagator
+	resultBound    int
+	resultIn       chan Result
+	resultsDropped atomic.Int64
+	resultsRelayed chan struct{}
+	summary        bool
+	started        time.Time
+	failed         atomic.Int64
+	progressOut    chan Progress
+	cache          *resultCache
+	stop           <-chan struct{}
+	commitFunc     func(seq int, value any)
+	commits        *committer
+	grace          time.Duration
+	lastActive     atomic.Int64 // UnixNano of the last Work received or finished
+	busyCount      atomic.Int64
+	agingEvery     time.Duration
+	agingBy        int
+	prio           *priorityQueue
+	cleanupLock    sync.Mutex
+	cleanups       []func()
+	cleanupTimeout time.Duration
+	ownProgress    bool
+	progressClosed chan struct{}
+	shuttingDown   atomic.Bool
+	supervised     atomic.Bool // from Supervisor until Reset
+	onStart        func(id any)
+	onEnd          func(id any)
 }
 
 // NewJob consumes a WorkerFunc to accomplish Work, and returns a Job.
-func NewJob(workerFunc WorkerFunc) Job {
-	return &defaultJob{
+// It panics if workerFunc is nil, rather than at the first dispatch.
+func NewJob(workerFunc WorkerFunc, opts ...JobOption) Job {
+	if workerFunc == nil {
+		panic("racket: NewJob called with a nil WorkerFunc")
+	}
+	return newJob(func(_ context.Context, id any, work Work, progressChan chan<- Progress) (any, error) {
+		workerFunc(id, work, progressChan)
+		return nil, nil
+	}, opts...)
+}
+
+// NewJobTimeout is NewJob, with each WorkerFunc invocation given perItem to finish (see WithItemTimeout). As a
+// WorkerFunc has no context, an invocation that overruns is abandoned to carry on in its goroutine, while a
+// ProgressTimeout (an error, per Progress.Error) is sent for it, and the worker moves on, so the Job can finish.
+// It panics if workerFunc is nil, or perItem isn't positive.
+func NewJobTimeout(workerFunc WorkerFunc, perItem time.Duration, opts ...JobOption) Job {
+	if perItem <= 0 {
+		panic(fmt.Sprintf("racket: NewJobTimeout called with a perItem of %s, not positive", perItem))
+	}
+	if workerFunc == nil {
+		panic("racket: NewJobTimeout called with a nil WorkerFunc")
+	}
+	return NewJob(workerFunc, append([]JobOption{WithItemTimeout(perItem)}, opts...)...)
+}
+
+// NewJobContext consumes a WorkerFuncContext to accomplish Work, and returns a Job.
+// It panics if workerFunc is nil, rather than at the first dispatch.
+func NewJobContext(workerFunc WorkerFuncContext, opts ...JobOption) Job {
+	if workerFunc == nil {
+		panic("racket: NewJobContext called with a nil WorkerFuncContext")
+	}
+	return newJob(func(ctx context.Context, id any, work Work, progressChan chan<- Progress) (any, error) {
+		workerFunc(ctx, id, work, progressChan)
+		return nil, nil
+	}, opts...)
+}
+
+// NewJobErr consumes a WorkerFuncErr to accomplish Work, and returns a Job.
+// It panics if workerFunc is nil, rather than at the first dispatch.
+func NewJobErr(workerFunc WorkerFuncErr, opts ...JobOption) Job {
+	if workerFunc == nil {
+		panic("racket: NewJobErr called with a nil WorkerFuncErr")
+	}
+	return newJob(func(_ context.Context, id any, work Work, _ chan<- Progress) (any, error) {
+		return nil, workerFunc(id, work)
+	}, opts...)
+}
+
+// NewJobAttempt consumes a WorkerFuncAttempt to accomplish Work, and returns a Job.
+// It panics if workerFunc is nil, rather than at the first dispatch.
+func NewJobAttempt(workerFunc WorkerFuncAttempt, opts ...JobOption) Job {
+	if workerFunc == nil {
+		panic("racket: NewJobAttempt called with a nil WorkerFuncAttempt")
+	}
+	return newJob(func(ctx context.Context, id any, work Work, _ chan<- Progress) (any, error) {
+		attempt, _ := ctx.Value(attemptKey{}).(int)
+		return nil, workerFunc(id, work, attempt)
+	}, opts...)
+}
+
+// NewJobRetry is NewJobErr, with Work whose WorkerFuncErr returns an error retried up to attempts times in total,
+// waiting backoff(attempt) between attempts (see WithRetry). The same Work is passed to each attempt, a
+// ProgressMessage is sent for each retry, and a ProgressError only after the final failure. As the doneFunc
+// returned by Supervisor stops retries, signal the end of the Work with DoneAllowRetries instead, to let every
+// item have its attempts. It panics if workerFunc is nil, or attempts is less than 1.
+func NewJobRetry(workerFunc WorkerFuncErr, attempts int, backoff func(attempt int) time.Duration, opts ...JobOption) Job {
+	if attempts < 1 {
+		panic(fmt.Sprintf("racket: NewJobRetry called with %d attempts, less than 1", attempts))
+	}
+	if workerFunc == nil {
+		panic("racket: NewJobRetry called with a nil WorkerFuncErr")
+	}
+	return NewJobErr(workerFunc, append([]JobOption{WithRetry(attempts, backoff)}, opts...)...)
+}
+
+// NewJobValue consumes a WorkerFuncValue to accomplish Work, and returns a Job.
+// It panics if workerFunc is nil, rather than at the first dispatch.
+func NewJobValue(workerFunc WorkerFuncValue, opts ...JobOption) Job {
+	if workerFunc == nil {
+		panic("racket: NewJobValue called with a nil WorkerFuncValue")
+	}
+	return newJob(workFunc(workerFunc), opts...)
+}
+
+// newJob returns a defaultJob for the workFunc, tuned by the JobOptions.
+func newJob(workerFunc workFunc, opts ...JobOption) *defaultJob {
+	j := &defaultJob{
 		workerFunc: workerFunc,
+		logger:     log.New(io.Discard, "", 0),
+	}
+	for _, opt := range opts {
+		opt(j)
 	}
+	return j
 }
 
 // NewWorker spins up a workerFunc to accomplish Work,
 // blocking until Work has been accomplished, or there is
 // no more to do.
 func (j *defaultJob) NewWorker(id any) {
-	defer j.lock.Unlock()
 	defer j.workerCount.Add(-1)
+	defer j.lifecycle(id)()
+
+	if w, seq, ok := j.receive(); ok {
+		j.work(id, w, seq)
+	}
+}
+
+// poolWorker is a long-lived worker for WithPooledWorkers, doing Work as it arrives until the Job is done.
+func (j *defaultJob) poolWorker(id any) {
+	defer j.workerCount.Add(-1)
+	defer j.lifecycle(id)()
+
+	for {
+		w, seq, ok := j.receive()
+		if !ok {
+			return
+		}
+		j.work(id, w, seq)
+	}
+}
+
+// lifecycle calls the WithOnStart hook for the worker, if any, and returns a func to defer, that calls the
+// WithOnEnd hook, if any, as the worker exits, whether or not it did any Work.
+func (j *defaultJob) lifecycle(id any) func() {
+	if j.onStart != nil {
+		j.onStart(id)
+	}
+	return func() {
+		if j.onEnd != nil {
+			j.onEnd(id)
+		}
+	}
+}
+
+// receive waits for Work, from the work channel, or the SupervisorPriority queue, returning false if the Job is done
+// first. Under NewCommitJob, Work is numbered, from 0, as it is received, which is the order it was sent.
+func (j *defaultJob) receive() (w Work, seq int, ok bool) {
+	if j.commits != nil {
+		// Only one receive at a time, so the numbering can't be raced.
+		j.commits.recvLock.Lock()
+		defer j.commits.recvLock.Unlock()
+	}
+
+	if j.prio != nil {
+		if w, ok = j.prio.pop(); !ok {
+			// The queue has drained after its doneFunc, or the Job is already done.
+			j.hardDone()
+			return w, 0, false
+		}
+	} else {
+		select {
+		case w = <-j.workChan:
+		case <-j.doneChan:
+			return w, 0, false
+		case <-j.drainChan:
+			select {
+			case w = <-j.workChan:
+			default:
+				// Drained.
+				j.done()
+				return w, 0, false
+			}
+		}
+	}
+
+	j.lastActive.Store(time.Now().UnixNano())
+	if j.commits != nil {
+		seq = j.commits.received
+		j.commits.received++
+	}
+	return w, seq, true
+}
+
+// work does a unit of Work received by the worker, and reports its Result, and hands it to the committer
+// under NewCommitJob.
+func (j *defaultJob) work(id any, w Work, seq int) {
+	j.busyCount.Add(1)
+	r := j.do(id, w)
+	j.lastActive.Store(time.Now().UnixNano())
+	j.busyCount.Add(-1)
+	if j.commits != nil {
+		j.commits.in <- sequenced{seq: seq, result: r}
+	}
+}
+
+// do does the Work, and reports and returns its Result.
+func (j *defaultJob) do(id any, w Work) Result {
+	if j.defaults.config != nil {
+		w = j.defaults.Merge(w)
+	}
+	if j.ctx.Err() != nil {
+		// The Job was cancelled before we got here, so don't bother.
+		j.progressChan <- PSkipped(SkipCancelled)
+		r := Result{Err: j.ctx.Err(), Status: ResultSkipped, Work: w}
+		j.result(r)
+		return r
+	}
+
+	if j.cache != nil {
+		if value, ok := j.cache.get(w); ok {
+			// Been there, done that.
+			j.progressChan <- PSkipped(SkipCacheHit)
+			j.completed.Add(1)
+			r := Result{Value: value, Status: ResultSuccess, Work: w}
+			j.result(r)
+			j.succeeded()
+			return r
+		}
+	}
+
+	start := time.Now()
+	value, attempts, err := j.busy(id, w, start)
+	j.completed.Add(1)
+	if err != nil {
+		j.failed.Add(1)
+		j.collectErr(err)
+	}
+	if j.completionLog {
+		j.logCompletion(CompletionEntry{ID: id, Work: w, Start: start})
+	}
+
+	r := Result{Value: value, Err: err, Work: w, Attempts: attempts, Duration: time.Since(start)}
+	switch {
+	case err == nil && j.ctx.Err() == nil:
+		r.Status = ResultSuccess
+	case err == nil, errors.Is(err, context.Canceled):
+		r.Status = ResultCancelled
+	case isTimeout(err):
+		r.Status = ResultTimedOut
+	default:
+		r.Status = ResultFailed
+	}
+	j.result(r)
+
+	if r.Status == ResultSuccess {
+		if j.cache != nil {
+			j.cache.put(w, value)
+		}
+		j.succeeded()
+	}
+	return r
+}
+
+// busy records the worker as busy with the Work since start, arming the WithWorkerWarnThreshold warning if set,
+// and processes the Work in a span, cleaning up after itself even if something panics.
+func (j *defaultJob) busy(id any, w Work, start time.Time) (value any, attempts int, err error) {
+	j.activeLock.Lock()
+	if j.active == nil {
+		j.active = make(map[any]WorkerInfo)
+	}
+	j.active[id] = WorkerInfo{Work: w, Start: start}
+	j.activeLock.Unlock()
+
+	if j.warnAfter > 0 {
+		timer := time.AfterFunc(j.warnAfter, func() {
+			j.logger.Printf("[RACKET] WARNING: worker %v has been running for over %s, and may be stuck\n", id, j.warnAfter)
+		})
+		defer timer.Stop()
+	}
+	defer func() {
+		j.activeLock.Lock()
+		delete(j.active, id)
+		j.activeLock.Unlock()
+	}()
+
+	ctx, endSpan := j.trace(j.ctx, w)
+	value, attempts, err = j.process(ctx, id, w)
+	endSpan(err)
+	return value, attempts, err
+}
+
+// ActiveWorkers returns a snapshot of the busy workers, by ID.
+func (j *defaultJob) ActiveWorkers() map[any]WorkerInfo {
+	j.activeLock.Lock()
+	defer j.activeLock.Unlock()
+
+	active := make(map[any]WorkerInfo, len(j.active))
+	for id, info := range j.active {
+		active[id] = info
+	}
+	return active
+}
+
+// LongestRunningWorker returns the ID of the longest-busy worker, and for how long, or nil and 0 if none are.
+func (j *defaultJob) LongestRunningWorker() (id any, dur time.Duration) {
+	j.activeLock.Lock()
+	defer j.activeLock.Unlock()
+
+	var (
+		earliest time.Time
+		found    bool
+	)
+	for wid, info := range j.active {
+		if !found || info.Start.Before(earliest) {
+			id, earliest, found = wid, info.Start, true
+		}
+	}
+	if !found {
+		return nil, 0
+	}
+	return id, time.Since(earliest)
+}
+
+// result sends the Result to the results channel, if WithResults was set, attaching its Context.
+func (j *defaultJob) result(r Result) {
+	if j.results == nil {
+		return
+	}
+
+	r.Context = context.Background()
+	if r.Status == ResultSkipped || r.Status == ResultCancelled {
+		// Give downstream an already-cancelled context, with the cause, that outlives the run.
+		cause := context.Cause(j.ctx)
+		if cause == nil {
+			cause = context.Canceled
+		}
+		var cancel context.CancelCauseFunc
+		r.Context, cancel = context.WithCancelCause(context.Background())
+		cancel(cause)
+	}
+	if j.resultIn != nil {
+		j.resultIn <- r
+		return
+	}
+	j.results <- r
+}
+
+// ResultsDropped returns how many Results have been dropped because the WithResultBuffer buffer was full.
+func (j *defaultJob) ResultsDropped() int64 {
+	return j.resultsDropped.Load()
+}
+
+// process runs the Work with the context, retrying failed attempts per WithRetry, and returns the final value and error, and the
+// number of attempts made. Only the final error is reported: as a ProgressTimeout if the item timed out, or else a ProgressError.
+func (j *defaultJob) process(ctx context.Context, id any, w Work) (value any, attempt int, err error) {
+	attempts := max(j.retryAttempts, 1)
+	for attempt = 1; attempt <= attempts; attempt++ {
+		if value, err = j.run(context.WithValue(ctx, attemptKey{}, attempt), id, w); err == nil || attempt == attempts || !j.backoff(id, attempt, attempts, err) {
+			break
+		}
+	}
+
+	var timedOut timeoutError
+	switch {
+	case errors.As(err, &timedOut):
+		j.progressChan <- Progress{Type: ProgressTimeout, Data: err}
+	case err != nil:
+		j.progressChan <- Progress{Type: ProgressError, Data: err}
+	}
+	return value, attempt, err
+}
+
+// backoff announces a retry, and waits out the backoff delay (and the retry budget, if any), returning false
+// if retries have been stopped (by done, or the shared context) and there should not be another attempt.
+func (j *defaultJob) backoff(id any, attempt, attempts int, err error) bool {
+	if !j.retrying() {
+		return false
+	}
+	j.progressChan <- PMessagef("worker %v: attempt %d of %d failed, retrying: %s", id, attempt, attempts, err)
+
+	var delay time.Duration
+	if j.retryBackoff != nil {
+		delay = j.retryBackoff(attempt)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
 
 	select {
-	case w := <-j.workChan:
-		j.workerFunc(id, w, j.progressChan)
-	case <-j.doneChan:
+	case <-timer.C:
+	case <-j.noRetries:
+		return false
+	case <-j.ctx.Done():
+		return false
+	}
+
+	if j.retryBudget != nil && !j.waitRetryBudget() {
+		return false
 	}
+	return j.retrying()
 }
 
-// IsDone waits until all of the workers have completed, kind of.
-// After done() has been called, if there are zero workers 4 consecutive 10ms polls,
-// we assume we are done.
-func (j *defaultJob) IsDone() <-chan bool {
-	b := make(chan bool)
+// waitRetryBudget waits for a token from the shared retry budget, returning false if retries were stopped
+// (by done, or the shared context) first.
+func (j *defaultJob) waitRetryBudget() bool {
+	ctx, cancel := context.WithCancel(j.ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-j.noRetries:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return j.retryBudget.wait(ctx)
+}
+
+// retrying returns true if failed attempts may still be retried.
+func (j *defaultJob) retrying() bool {
+	select {
+	case <-j.noRetries:
+		return false
+	default:
+		return j.ctx.Err() == nil
+	}
+}
+
+// run calls the workerFunc with the context, returning its value and error, or a timeoutError if it timed out.
+// If WithRateLimit is set, the call waits for the Job's rate limiter first, and then if WithPerWorkerRate is set,
+// for the worker's own.
+// If WithItemTimeout is set, the workerFunc is run in its own goroutine and raced against the deadline:
+// on expiry the goroutine is abandoned, though it is counted in calls until it returns.
+func (j *defaultJob) run(ctx context.Context, id any, w Work) (any, error) {
+	if j.rate != nil && !j.rate.wait(ctx) {
+		return nil, ctx.Err()
+	}
+	if j.workerRates != nil && !j.workerRates.get(id).wait(ctx) {
+		return nil, ctx.Err()
+	}
+
+	if j.itemTimeout <= 0 {
+		return j.call(ctx, id, w)
+	}
 
+	ctx, cancel := context.WithTimeout(ctx, j.itemTimeout)
+	defer cancel()
+
+	type valErr struct {
+		value any
+		err   error
+	}
+	result := make(chan valErr, 1)
+	j.calls.Add(1)
 	go func() {
-		var count int
-		<-j.doneChan // if doneChan isn't closed, we are definitely not done
+		defer j.calls.Done()
+		v, err := j.call(ctx, id, w)
+		result <- valErr{v, err}
+	}()
 
-		for {
-			if j.workerCount.Load() > 0 {
-				count = 0
-			} else {
-				count++
+	select {
+	case r := <-result:
+		return r.value, r.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, timeoutError{fmt.Errorf("worker %v timed out after %s", id, j.itemTimeout)}
+		}
+		// The Job was cancelled, not timed out: wait as we would without a timeout.
+		r := <-result
+		return r.value, r.err
+	}
+}
+
+// call calls the workerFunc, returning its value and error. A panic is recovered, and returned as an error.
+// Under PanicAbort, a panic also aborts the Job.
+func (j *defaultJob) call(ctx context.Context, id any, w Work) (value any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			perr := fmt.Errorf("worker %v panicked: %v", id, r)
+			if j.panicStack {
+				perr = fmt.Errorf("%w\n%s", perr, debug.Stack())
 			}
-			if count > 4 {
-				break
+			if j.panicPolicy == PanicAbort {
+				j.abort(perr)
 			}
-			<-time.After(10 * time.Millisecond)
+			err = perr
+		}
+	}()
+
+	return j.workerFunc(ctx, id, w, j.progressChan)
+}
+
+// timeoutError is an error because a deadline expired.
+type timeoutError struct {
+	error
+}
+
+// Timeout returns true.
+func (t timeoutError) Timeout() bool {
+	return true
+}
+
+// Unwrap returns the underlying error.
+func (t timeoutError) Unwrap() error {
+	return t.error
+}
+
+// isTimeout returns true if the error, or one it wraps, has a Timeout method that returns true (e.g. net.Error).
+func isTimeout(err error) bool {
+	var t interface{ Timeout() bool }
+	return errors.As(err, &t) && t.Timeout()
+}
+
+// abort records the first error to abort the Job, cancels the shared context, and signals done.
+func (j *defaultJob) abort(err error) {
+	j.errLock.Lock()
+	if j.err == nil {
+		j.err = err
+	}
+	j.errLock.Unlock()
+
+	j.cancel()
+	j.done()
+}
+
+// Err returns the error that aborted the Job, if any.
+func (j *defaultJob) Err() error {
+	j.errLock.Lock()
+	defer j.errLock.Unlock()
+	return j.err
+}
+
+// Errors returns a copy of the errors from the Work that failed.
+func (j *defaultJob) Errors() []error {
+	j.errLock.Lock()
+	defer j.errLock.Unlock()
+	return append([]error(nil), j.errs...)
+}
+
+// collectErr adds the error from failed Work to Errors.
+func (j *defaultJob) collectErr(err error) {
+	j.errLock.Lock()
+	defer j.errLock.Unlock()
+	j.errs = append(j.errs, err)
+}
+
+// logCompletion stamps the End of the entry, and appends it to the completion log.
+func (j *defaultJob) logCompletion(entry CompletionEntry) {
+	j.logLock.Lock()
+	defer j.logLock.Unlock()
+	entry.End = time.Now()
+	j.log = append(j.log, entry)
+}
+
+// CompletionLog returns a copy of the completion log, in order of completion.
+// It is always empty unless the Job was created WithCompletionLog.
+func (j *defaultJob) CompletionLog() []CompletionEntry {
+	j.logLock.Lock()
+	defer j.logLock.Unlock()
+	return append([]CompletionEntry(nil), j.log...)
+}
+
+// succeeded accounts for Work whose Result is a ResultSuccess, stopping the Job if StopAfterSuccesses has been
+// satisfied.
+func (j *defaultJob) succeeded() {
+	if n := j.successes.Add(1); j.stopAfter > 0 && n == j.stopAfter {
+		j.cancel()
+		j.done()
+	}
+}
+
+// done signals the Supervisor and any idle workers that there is no more Work. It is safe to call more than once.
+func (j *defaultJob) done() {
+	j.doneOnce.Do(func() { close(j.doneChan) })
+}
+
+// hardDone signals done, and also that in-flight Work should not be retried after its current attempt.
+// It is safe to call more than once.
+func (j *defaultJob) hardDone() {
+	j.noRetriesOnce.Do(func() { close(j.noRetries) })
+	j.done()
+}
+
+// Drain signals that no more Work will be sent, and done once the queued Work has been received. It does nothing if
+// the Job hasn't been Supervised.
+func (j *defaultJob) Drain() {
+	if j.drainChan == nil {
+		return
+	}
+
+	j.drainOnce.Do(func() {
+		if j.prio != nil {
+			// The queue already drains before done.
+			j.prio.close()
+			return
+		}
+		close(j.drainChan)
+	})
+}
+
+// Shutdown cancels the Job, signals done, and waits for the workers to leave, and any buffered Progress and Results
+// to be delivered, then sends a Summary as the final Progress if WithSummary was set. It does nothing if the Job
+// hasn't been Supervised.
+func (j *defaultJob) Shutdown() {
+	if j.finished == nil {
+		return
+	}
+
+	j.shuttingDown.Store(true)
+	j.cancel()
+	j.hardDone()
+	<-j.finished
+	if j.relayed != nil {
+		<-j.relayed
+	}
+	if j.resultsRelayed != nil {
+		<-j.resultsRelayed
+	}
+
+	j.runCleanups()
+
+	if j.progressClosed != nil {
+		// The summary, if any, is sent before the channel is closed.
+		<-j.progressClosed
+	} else if j.summary {
+		j.progressOut <- j.summarize()
+	}
+}
+
+// summarize returns the ProgressEvent of the Summary of the Job so far.
+func (j *defaultJob) summarize() Progress {
+	return PEvent(EventSummary, Summary{
+		Completed: j.completed.Load(),
+		Succeeded: j.successes.Load(),
+		Failed:    j.failed.Load(),
+		Duration:  time.Since(j.started),
+	})
+}
+
+// closeProgress closes the progress channel for WithOwnedProgress, once the workers, and any abandoned
+// WithItemTimeout invocations, have finished, and any buffered Progress has been relayed, sending the Summary first
+// if the Job is being Shutdown with WithSummary.
+func (j *defaultJob) closeProgress(finished, relayed, closed chan struct{}, out chan Progress) {
+	defer close(closed)
+
+	<-finished
+	j.calls.Wait() // they may still send Progress
+	if relayed != nil {
+		<-relayed
+	}
+	if j.summary && j.shuttingDown.Load() {
+		out <- j.summarize()
+	}
+	close(out)
+}
+
+// RegisterCleanup registers a func for Shutdown to run.
+func (j *defaultJob) RegisterCleanup(cleanup func()) {
+	j.cleanupLock.Lock()
+	defer j.cleanupLock.Unlock()
+
+	j.cleanups = append(j.cleanups, cleanup)
+}
+
+// runCleanups runs the registered cleanups concurrently, once, and waits for them up to the cleanup timeout,
+// warning about any still running after it.
+func (j *defaultJob) runCleanups() {
+	j.cleanupLock.Lock()
+	cleanups := j.cleanups
+	j.cleanups = nil
+	j.cleanupLock.Unlock()
+
+	if len(cleanups) == 0 {
+		return
+	}
+
+	timeout := j.cleanupTimeout
+	if timeout <= 0 {
+		timeout = defaultCleanupTimeout
+	}
+
+	var running atomic.Int64
+	running.Store(int64(len(cleanups)))
+	cleaned := make(chan struct{})
+	for _, cleanup := range cleanups {
+		go func() {
+			defer func() {
+				if running.Add(-1) == 0 {
+					close(cleaned)
+				}
+			}()
+			cleanup()
+		}()
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-cleaned:
+	case <-timer.C:
+		j.logger.Printf("[RACKET] WARNING: %d cleanups still running after %s, giving up on them\n", running.Load(), timeout)
+	}
+}
+
+// DoneAllowRetries signals done, like the doneFunc returned by Supervisor, but lets in-flight Work complete
+// its remaining retry attempts. It is safe to call more than once, and in combination with the doneFunc.
+func (j *defaultJob) DoneAllowRetries() {
+	j.done()
+}
+
+// IsDone waits until done has been signaled, and every worker that was spawned has returned, then for any
+// buffered Progress and Results to be delivered, and commits made, before firing.
+func (j *defaultJob) IsDone() <-chan bool {
+	b := make(chan bool, 1) // so we don't linger if the caller stops waiting, e.g. IsDoneTimeout
+	finished := j.finished
+	relayed := j.relayed
+	resultsRelayed := j.resultsRelayed
+	commits := j.commits
+	progressClosed := j.progressClosed
+
+	go func() {
+		// finished is only closed after done, once the supervisor has stopped spawning, and its workers have all
+		// returned, so there is no polling, and no window where a worker is starting but not yet counted.
+		<-finished
+		if relayed != nil {
+			<-relayed // all buffered Progress is delivered, so the consumer may close the channel
+		}
+		if resultsRelayed != nil {
+			<-resultsRelayed // likewise Results
+		}
+		if commits != nil {
+			<-commits.committed // and commits
+		}
+		if progressClosed != nil {
+			<-progressClosed // and under WithOwnedProgress, the progress channel is closed
 		}
 		b <- true
 	}()
@@ -103,23 +990,159 @@ func (j *defaultJob) IsDone() <-chan bool {
 	return b
 }
 
+// IsDoneTimeout is IsDone, but sends false if the Job isn't done within the timeout.
+func (j *defaultJob) IsDoneTimeout(timeout time.Duration) <-chan bool {
+	b := make(chan bool, 1)
+	done := j.IsDone()
+
+	go func() {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		select {
+		case <-done:
+			b <- true
+		case <-timer.C:
+			b <- false
+		}
+	}()
+
+	return b
+}
+
 // Supervisor spins up maxWorkers, who will wait for Work via workChan, and returns a channel for
 // progress reciepts and func to signal when there is no new Work to be added to workChan.
+// With a maxWorkers of 1, Work is processed strictly in the order it was sent: a new worker isn't started
+// until the previous one has finished and released the lock, so only one receive from workChan can be pending.
+// It panics if maxWorkers is less than 1, rather than hanging.
 func (j *defaultJob) Supervisor(maxWorkers int, workChan chan Work) (progressChan chan Progress, doneFunc func()) {
+	if maxWorkers < 1 {
+		// Otherwise the lock never grants, and the Job silently hangs.
+		panic(fmt.Sprintf("racket: Supervisor called with a maxWorkers of %d, less than 1", maxWorkers))
+	}
+	return j.SupervisorContext(context.Background(), maxWorkers, workChan)
+}
+
+// SupervisorContext is Supervisor, but the Job is hard-cancelled, as if by WithStopChannel, when the ctx is done:
+// no more workers are spawned, and the Job's context, as handed to a WorkerFuncContext, is cancelled.
+// It panics if maxWorkers is less than 1, rather than hanging.
+func (j *defaultJob) SupervisorContext(ctx context.Context, maxWorkers int, workChan chan Work) (progressChan chan Progress, doneFunc func()) {
+	if maxWorkers < 1 {
+		panic(fmt.Sprintf("racket: SupervisorContext called with a maxWorkers of %d, less than 1", maxWorkers))
+	}
+	if !j.supervised.CompareAndSwap(false, true) {
+		panic(alreadySupervised)
+	}
+
 	j.doneChan = make(chan struct{})
-	j.progressChan = make(chan Progress)
+	j.noRetries = make(chan struct{})
+	j.drainChan = make(chan struct{})
+	j.ctx, j.cancel = context.WithCancel(ctx)
+	j.progressChan = make(chan Progress, j.progressBuffer)
+	progressChan = j.progressChan
+	j.progressOut = progressChan
+	j.started = time.Now()
+	j.finished = make(chan struct{})
 	j.workChan = workChan
-	j.lock = semaphore.NewSemaphore(maxWorkers)
+	j.sizeLock.Lock()
+	lock := semaphore.NewSemaphore(maxWorkers)
+	j.lock = &lock
+	j.slots = make(chan int, maxWorkers)
+	for i := range maxWorkers {
+		j.slots <- i
+	}
+	j.maxWorkers = maxWorkers
+	j.retiring = make(map[int]struct{})
+	j.resized = make(chan struct{}, 1)
+	j.sizeLock.Unlock()
+
+	if j.stop != nil {
+		go j.watchStop(j.stop, j.finished, j.cancel)
+	}
+
+	if ctx.Done() != nil {
+		go j.watchStop(ctx.Done(), j.finished, j.cancel)
+	}
+
+	if j.grace > 0 {
+		j.lastActive.Store(time.Now().UnixNano())
+		go j.watchIdle(j.finished)
+	}
+
+	if j.commitFunc != nil {
+		j.commits = newCommitter(j.commitFunc)
+		go j.commits.run()
+	}
+
+	if j.resultBound > 0 && j.results != nil {
+		// Workers send to an unbuffered channel, relayed through the buffer to the results channel.
+		j.resultIn = make(chan Result)
+		j.resultsRelayed = make(chan struct{})
+		go func(in <-chan Result, relayed chan struct{}) {
+			defer close(relayed)
+			relay(in, j.results, j.resultBound, OverflowDropOldest, &j.resultsDropped)
+		}(j.resultIn, j.resultsRelayed)
+	}
+
+	if j.bufferProgress {
+		// Workers send to an unbuffered channel, relayed through the buffer to the one we return.
+		j.progressChan = make(chan Progress)
+		j.relayed = make(chan struct{})
+		go j.relayProgress(j.progressChan, progressChan, j.relayed)
+	} else if j.progressBuffer > 0 {
+		go j.watchProgress(j.progressChan, j.finished)
+	}
+
+	if j.ownProgress {
+		j.progressClosed = make(chan struct{})
+		go j.closeProgress(j.finished, j.relayed, j.progressClosed, progressChan)
+	}
+
+	if j.pooled {
+		// A fixed set of long-lived workers, one per slot.
+		j.workerCount.Add(int64(maxWorkers))
+		j.wg.Add(maxWorkers)
+		for slot := range maxWorkers {
+			id := j.workerID(slot)
+			go func() {
+				defer j.wg.Done()
+				j.poolWorker(id)
+			}()
+		}
+		go func() {
+			defer j.finish()
+			<-j.doneChan
+		}()
+		return progressChan, j.hardDone
+	}
 
 	go func() {
-		c := 0
+		defer j.finish()
 		for {
-			c++
+			j.sizeLock.Lock()
+			slots, lock := j.slots, j.lock
+			j.sizeLock.Unlock()
+
 			select {
-			case <-j.lock.Until():
-				// woo! make a worker!
+			case slot := <-slots:
+				if !j.claim(slot) {
+					continue // retired by Resize
+				}
+				// woo! make a worker! A slot is only returned after its worker has released the lock, so this won't
+				// block. Waiting on the slot rather than lock.Until, which gives the lock back if we're slow to
+				// receive it (e.g. under load), and then never fires.
+				lock.Lock()
 				j.workerCount.Add(1)
-				go j.NewWorker(c)
+				j.wg.Add(1)
+				id := j.workerID(slot)
+				go func() {
+					defer j.wg.Done()
+					defer j.release(slot)
+					defer lock.Unlock()
+					j.NewWorker(id)
+				}()
+			case <-j.resized:
+				// Pick up the new slots.
 			case <-j.doneChan:
 				// That's all folks!
 				return
@@ -127,5 +1150,205 @@ func (j *defaultJob) Supervisor(maxWorkers int, workChan chan Work) (progressCha
 		}
 	}()
 
-	return j.progressChan, func() { close(j.doneChan) }
+	return progressChan, j.hardDone
+}
+
+// workerID returns the ID for a worker in the slot, from the IDAllocator if WithIDAllocator was set.
+func (j *defaultJob) workerID(slot int) any {
+	if j.ids != nil {
+		return j.ids.Next()
+	}
+	return slot
+}
+
+// watchStop hard-cancels the Job when the stop channel is closed, unless the Job finishes first.
+func (j *defaultJob) watchStop(stop <-chan struct{}, finished chan struct{}, cancel context.CancelFunc) {
+	select {
+	case <-stop:
+		select {
+		case <-finished:
+			// Too late, and we may have been Reset.
+		default:
+			cancel()
+			j.hardDone()
+		}
+	case <-finished:
+	}
+}
+
+// watchIdle signals done once the Job has been idle, with no Work received and no worker busy, for the full
+// CompletionGrace, unless the Job finishes first.
+func (j *defaultJob) watchIdle(finished chan struct{}) {
+	ticker := time.NewTicker(max(j.grace/10, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-finished:
+			return
+		case <-ticker.C:
+			idle := time.Since(time.Unix(0, j.lastActive.Load()))
+			if j.busyCount.Load() == 0 && idle >= j.grace {
+				j.done()
+				return
+			}
+		}
+	}
+}
+
+// finish waits for every worker the Supervisor spawned to return, releases the shared context,
+// and then closes the finished channel. Under WithBufferedProgress, the relay is let drain once any abandoned
+// WithItemTimeout invocations have also returned, as they may still send Progress.
+func (j *defaultJob) finish() {
+	j.wg.Wait()
+	j.cancel()
+	if j.bufferProgress {
+		go func(in chan Progress) {
+			j.calls.Wait()
+			close(in) // the workers are gone, so let the relay drain
+		}(j.progressChan)
+	}
+	if j.resultIn != nil {
+		close(j.resultIn)
+	}
+	if j.commits != nil {
+		close(j.commits.in)
+	}
+	close(j.finished)
+}
+
+// relayProgress relays Progress from the workers to the consumer per WithBufferedProgress, until the workers are
+// gone and the buffer drained, then warns if any were dropped, and closes relayed.
+func (j *defaultJob) relayProgress(in <-chan Progress, out chan<- Progress, relayed chan struct{}) {
+	defer close(relayed)
+
+	var dropped atomic.Int64
+	relay(in, out, j.progressBound, j.progressPolicy, &dropped)
+	if n := dropped.Load(); n > 0 {
+		j.logger.Printf("[RACKET] WARNING: %d progress dropped as the progress buffer was full\n", n)
+	}
+}
+
+// CompletedCount returns how many units of Work the workers have finished, whatever the outcome, including cache hits.
+func (j *defaultJob) CompletedCount() int64 {
+	return j.completed.Load()
+}
+
+// Completed returns how many units of Work have completed successfully.
+func (j *defaultJob) Completed() int64 {
+	return j.successes.Load()
+}
+
+// Stats returns a snapshot of how the Job is doing.
+func (j *defaultJob) Stats() JobStats {
+	stats := JobStats{
+		ActiveWorkers:  j.workerCount.Load(),
+		BusyWorkers:    j.busyCount.Load(),
+		CompletedCount: j.completed.Load(),
+	}
+	if j.doneChan != nil {
+		select {
+		case <-j.doneChan:
+			stats.Done = true
+		default:
+		}
+	}
+	return stats
+}
+
+// Reset clears the Job's counters, completion log, and error, preparing it for another Supervisor
+// with the same WorkerFunc and options. It returns ErrJobRunning if the Job has been Supervised and
+// isn't yet done, as IsDone: its workers have not all finished, or its Progress and Results relays, commits, or
+// closing of an owned progress channel are still going.
+func (j *defaultJob) Reset() error {
+	if j.running() {
+		return ErrJobRunning
+	}
+
+	j.doneChan = nil
+	j.doneOnce = sync.Once{}
+	j.noRetries = nil
+	j.noRetriesOnce = sync.Once{}
+	j.drainChan = nil
+	j.drainOnce = sync.Once{}
+	j.finished = nil
+	j.relayed = nil
+	j.resultIn = nil
+	j.resultsRelayed = nil
+	j.resultsDropped.Store(0)
+	j.commits = nil
+	j.prio = nil
+	j.progressClosed = nil
+	j.shuttingDown.Store(false)
+	j.supervised.Store(false)
+	j.successes.Store(0)
+	j.completed.Store(0)
+	j.failed.Store(0)
+
+	j.logLock.Lock()
+	j.log = nil
+	j.logLock.Unlock()
+
+	j.cleanupLock.Lock()
+	j.cleanups = nil
+	j.cleanupLock.Unlock()
+
+	j.errLock.Lock()
+	j.err = nil
+	j.errs = nil
+	j.errLock.Unlock()
+
+	return nil
+}
+
+// running returns true if the Job has been Supervised, and it, or any of the goroutines IsDone waits for, haven't
+// finished.
+func (j *defaultJob) running() bool {
+	pending := []chan struct{}{j.finished, j.relayed, j.resultsRelayed, j.progressClosed}
+	if j.commits != nil {
+		pending = append(pending, j.commits.committed)
+	}
+	for _, c := range pending {
+		if c == nil {
+			continue
+		}
+		select {
+		case <-c:
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// watchProgress samples the buffered progress channel, and emits a rate-limited warning to the logger
+// if it stays near-full, as the progress consumer is too slow and workers will soon block on it, until the Job
+// finishes.
+func (j *defaultJob) watchProgress(progressChan chan Progress, finished chan struct{}) {
+	var (
+		nearFull  = cap(progressChan) - cap(progressChan)/10
+		count     int
+		lastWarn  time.Time
+		ticker    = time.NewTicker(progressWatchInterval)
+		threshold = 5 // consecutive near-full samples
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-finished:
+			return
+		case <-ticker.C:
+			if len(progressChan) < nearFull {
+				count = 0
+				continue
+			}
+			count++
+			if count >= threshold && time.Since(lastWarn) > progressWarnInterval {
+				lastWarn = time.Now()
+				j.logger.Printf("[RACKET] WARNING: progress channel is near full (%d of %d), the progress consumer may be too slow\n",
+					len(progressChan), cap(progressChan))
+			}
+		}
+	}
 }
@@ -1,16 +1,44 @@
 package racket
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"log"
+	"math"
 	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/fortytw2/leaktest"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+// syncBuffer is a goro-safe bytes.Buffer, for capturing log output.
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
 func Example() {
 	var (
 		wCount atomic.Int64                // atomic counter
@@ -81,3 +109,1970 @@ func Test_Job(t *testing.T) {
 		c.So(wCount.Load(), ShouldEqual, its)
 	})
 }
+
+func Test_JobStopAfterSuccesses(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 10
+
+	Convey("When a Job is set to StopAfterSuccesses, it stops after that many successes, cancelling the rest.", t, func(c C) {
+		var (
+			wCount       atomic.Int64
+			cancelCount  atomic.Int64
+			successCount = 3
+		)
+
+		wf := func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+			if work.GetInt("n") < successCount {
+				// the fast ones
+				wCount.Add(1)
+				return
+			}
+			select {
+			case <-ctx.Done():
+				cancelCount.Add(1)
+			case <-time.After(5 * time.Second):
+				wCount.Add(1)
+			}
+		}
+
+		j := NewJobContext(wf, StopAfterSuccesses(successCount))
+		wchan := make(chan Work, its)
+		pchan, done := j.Supervisor(its, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		start := time.Now()
+		for i := range its {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+
+		<-j.IsDone()
+		done() // safe to call after the Job has stopped itself
+
+		c.So(time.Since(start), ShouldBeLessThan, time.Second)
+		c.So(wCount.Load(), ShouldEqual, successCount)
+		c.So(cancelCount.Load(), ShouldBeLessThanOrEqualTo, its-successCount)
+	})
+	Convey("When Work fails under StopAfterSuccesses, it doesn't count as a success.", t, func() {
+		j := NewJobErr(func(id any, work Work) error {
+			if work.GetBool("fail") {
+				return errors.New("nope")
+			}
+			return nil
+		}, StopAfterSuccesses(2))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(map[string]any{"fail": true})
+		wchan <- NewWork(nil)
+		wchan <- NewWork(map[string]any{"fail": true})
+		So(<-j.IsDoneTimeout(50*time.Millisecond), ShouldBeFalse) // one success isn't enough
+
+		wchan <- NewWork(nil)
+		<-j.IsDone()
+		done()
+		So(j.Completed(), ShouldEqual, 2)
+		So(j.CompletedCount(), ShouldEqual, 4)
+	})
+}
+
+func Test_JobWithDefaults(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	Convey("When a Job has default Work, it is merged under each item before dispatch.", t, func(c C) {
+		var (
+			lock sync.Mutex
+			seen = make(map[string]string)
+		)
+
+		wf := func(id any, work Work, pchan chan<- Progress) {
+			lock.Lock()
+			defer lock.Unlock()
+			seen[work.GetString("name")] = work.GetString("timeout")
+		}
+
+		j := NewJob(wf, WithDefaults(NewWork(map[string]any{"timeout": "30s"})))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(map[string]any{"name": "defaulted"})
+		wchan <- NewWork(map[string]any{"name": "overridden", "timeout": "5s"})
+		done()
+
+		<-j.IsDone()
+
+		lock.Lock()
+		defer lock.Unlock()
+		c.So(seen, ShouldHaveLength, 2)
+		c.So(seen["defaulted"], ShouldEqual, "30s")
+		c.So(seen["overridden"], ShouldEqual, "5s")
+	})
+}
+
+func Test_JobDiagnostics(t *testing.T) {
+
+	Convey("Every kind of Job has JobDiagnostics.", t, func() {
+		wf := func(id any, work Work, pchan chan<- Progress) {}
+		rf := func(id any, work Work) (int, error) { return 0, nil }
+		for _, j := range []Job{
+			NewJob(wf),
+			NewJobTimeout(wf, time.Second),
+			NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {}),
+			NewJobErr(func(id any, work Work) error { return nil }),
+			NewJobAttempt(func(id any, work Work, attempt int) error { return nil }),
+			NewJobRetry(func(id any, work Work) error { return nil }, 1, nil),
+			NewJobValue(func(ctx context.Context, id any, work Work, pchan chan<- Progress) (any, error) { return nil, nil }),
+			NewJobResult(rf),
+			NewOrderedJob(rf),
+			NewCommitJob(rf, func(seq int, value int) {}),
+		} {
+			_, ok := j.(JobDiagnostics)
+			So(ok, ShouldBeTrue)
+		}
+	})
+}
+
+func Test_JobCompletionLog(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 10
+
+	Convey("When a Job has no completion log, it is empty.", t, func(c C) {
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(nil)
+		done()
+		<-j.IsDone()
+
+		c.So(j.(JobDiagnostics).CompletionLog(), ShouldBeEmpty)
+	})
+
+	Convey("When a Job has a completion log, it records each item in order of completion.", t, func(c C) {
+		wf := func(id any, work Work, pchan chan<- Progress) {
+			time.Sleep(time.Duration(work.GetInt("n")) * time.Millisecond)
+		}
+
+		j := NewJob(wf, WithCompletionLog())
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(3, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for i := range its {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+		done()
+		<-j.IsDone()
+
+		entries := j.(JobDiagnostics).CompletionLog()
+		c.So(entries, ShouldHaveLength, its)
+
+		seen := make(map[int]bool)
+		for i, e := range entries {
+			seen[e.Work.GetInt("n")] = true
+			c.So(e.End, ShouldHappenOnOrAfter, e.Start)
+			if i > 0 {
+				c.So(e.End, ShouldHappenOnOrAfter, entries[i-1].End)
+			}
+		}
+		c.So(seen, ShouldHaveLength, its)
+	})
+}
+
+func Test_JobWorkerSlotIDs(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 100
+	maxWorkers := 3
+
+	Convey("When a Job has Work assigned, worker IDs are stable concurrency slots.", t, func(c C) {
+		var (
+			lock sync.Mutex
+			ids  = make(map[any]int)
+		)
+
+		wf := func(id any, work Work, pchan chan<- Progress) {
+			lock.Lock()
+			defer lock.Unlock()
+			ids[id]++
+		}
+
+		j := NewJob(wf)
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(maxWorkers, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		done()
+		<-j.IsDone()
+
+		lock.Lock()
+		defer lock.Unlock()
+		var total int
+		for id, count := range ids {
+			c.So(id, ShouldHaveSameTypeAs, 0)
+			c.So(id, ShouldBeBetweenOrEqual, 0, maxWorkers-1)
+			total += count
+		}
+		c.So(total, ShouldEqual, its)
+	})
+}
+
+// stringIDs is an IDAllocator of prefixed string IDs.
+type stringIDs struct {
+	prefix string
+	last   atomic.Int64
+}
+
+func (s *stringIDs) Next() any {
+	return fmt.Sprintf("%s-%d", s.prefix, s.last.Add(1))
+}
+
+func Test_JobIDAllocator(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 20
+	maxWorkers := 3
+
+	Convey("When a Job has an IDAllocator, workers receive the IDs it generates.", t, func(c C) {
+		var (
+			lock sync.Mutex
+			ids  = make(map[any]int)
+		)
+
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			lock.Lock()
+			defer lock.Unlock()
+			ids[id]++
+		}, WithIDAllocator(&stringIDs{prefix: "worker"}))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(maxWorkers, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		done()
+		<-j.IsDone()
+
+		lock.Lock()
+		defer lock.Unlock()
+		c.So(ids, ShouldHaveLength, its) // a fresh ID for every worker started
+		for id := range ids {
+			c.So(id, ShouldStartWith, "worker-")
+		}
+	})
+
+	Convey("SequentialIDs are incrementing ints, starting at 1.", t, func() {
+		var s SequentialIDs
+		So(s.Next(), ShouldEqual, 1)
+		So(s.Next(), ShouldEqual, 2)
+		So(s.Next(), ShouldEqual, 3)
+	})
+}
+
+func Test_JobProgressBufferWarning(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a Job has a buffered progress channel and a slow consumer, a warning is logged.", t, func(c C) {
+		var out syncBuffer
+
+		wf := func(id any, work Work, pchan chan<- Progress) {
+			for i := range 10 {
+				pchan <- PUpdate(int64(i))
+			}
+		}
+
+		j := NewJob(wf, WithProgressBuffer(2), WithLogger(log.New(&out, "", 0)))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+		defer close(pchan)
+		go func() {
+			// a very slow consumer
+			for range pchan {
+				time.Sleep(100 * time.Millisecond)
+			}
+		}()
+
+		wchan <- NewWork(nil)
+		done()
+		<-j.IsDone()
+
+		c.So(out.String(), ShouldContainSubstring, "progress channel is near full (2 of 2)")
+	})
+}
+
+func Test_JobPanicPolicy(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 10
+
+	wf := func(wCount *atomic.Int64) WorkerFunc {
+		return func(id any, work Work, pchan chan<- Progress) {
+			if work.GetBool("panic") {
+				panic("at the disco")
+			}
+			wCount.Add(1)
+		}
+	}
+
+	Convey("When a WorkerFunc panics under PanicRecover, the error is reported and the Job continues.", t, func(c C) {
+		var (
+			wCount atomic.Int64
+			eCount atomic.Int64
+		)
+
+		j := NewJob(wf(&wCount))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, func(e error) {
+			c.So(e.Error(), ShouldContainSubstring, "panicked: at the disco")
+			eCount.Add(1)
+		}, pchan, nil)
+
+		for i := range its {
+			wchan <- NewWork(map[string]any{"panic": i == 3})
+		}
+		done()
+		<-j.IsDone()
+
+		c.So(wCount.Load(), ShouldEqual, its-1)
+		// IsDone doesn't wait for the consumer, so the last errf may still be running.
+		c.So(waitFor(time.Second, func() bool { return eCount.Load() == 1 }), ShouldBeTrue)
+		c.So(j.Err(), ShouldBeNil)
+	})
+
+	Convey("When a WorkerFunc panics with WithPanicStack, the error includes the stack trace.", t, func(c C) {
+		var (
+			wCount atomic.Int64
+			errs   = make(chan error, 1)
+		)
+
+		j := NewJob(wf(&wCount), WithPanicStack())
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, func(e error) {
+			errs <- e
+		}, pchan, nil)
+
+		wchan <- NewWork(map[string]any{"panic": true})
+		wchan <- NewWork(nil)
+		done()
+		<-j.IsDone()
+
+		c.So(wCount.Load(), ShouldEqual, 1)
+		e := <-errs
+		c.So(e.Error(), ShouldStartWith, "worker ")
+		c.So(e.Error(), ShouldContainSubstring, "panicked: at the disco\ngoroutine ")
+		c.So(e.Error(), ShouldContainSubstring, "job_test.go")
+	})
+
+	Convey("When a WorkerFunc panics under PanicAbort, the Job is aborted.", t, func(c C) {
+		var (
+			wCount atomic.Int64
+			eCount atomic.Int64
+		)
+
+		j := NewJob(wf(&wCount), WithPanicPolicy(PanicAbort))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, func(e error) {
+			eCount.Add(1)
+		}, pchan, nil)
+
+		wchan <- NewWork(map[string]any{"panic": true})
+		<-j.IsDone() // no done() needed, the Job aborted
+		done()
+
+		c.So(wCount.Load(), ShouldEqual, 0)
+		// IsDone doesn't wait for the consumer, so the last errf may still be running.
+		c.So(waitFor(time.Second, func() bool { return eCount.Load() == 1 }), ShouldBeTrue)
+		c.So(j.Err(), ShouldBeError)
+		c.So(j.Err().Error(), ShouldContainSubstring, "panicked: at the disco")
+	})
+}
+
+func Test_JobErrors(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 20
+
+	Convey("When half of the Work fails, Errors has exactly those errors.", t, func() {
+		j := NewJobErr(func(id any, work Work) error {
+			if n := work.GetInt("n"); n%2 == 1 {
+				return fmt.Errorf("odd %d", n)
+			}
+			return nil
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(4, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for i := range its {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+		done()
+		<-j.IsDone()
+
+		var got, expected []string
+		for _, e := range j.Errors() {
+			got = append(got, e.Error())
+		}
+		for i := 1; i < its; i += 2 {
+			expected = append(expected, fmt.Sprintf("odd %d", i))
+		}
+		So(got, ShouldHaveLength, its/2)
+		sort.Strings(got)
+		sort.Strings(expected)
+		So(got, ShouldResemble, expected)
+
+		Convey("... and Reset clears them", func() {
+			So(j.Reset(), ShouldBeNil)
+			So(j.Errors(), ShouldBeEmpty)
+		})
+	})
+}
+
+func Test_JobIsDoneStress(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	jobs := 200
+	its := 20
+
+	Convey("When many fast Jobs are run back-to-back, IsDone never fires before all of the Work is done, nor lingers.", t, func() {
+		start := time.Now()
+		for range jobs {
+			var count atomic.Int64
+			j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+				count.Add(1)
+			})
+			wchan := make(chan Work)
+			pchan, done := j.Supervisor(4, wchan)
+			go ProgressLogger(disco, false, nil, pchan, nil)
+
+			for range its {
+				wchan <- NewWork(nil)
+			}
+			done()
+			<-j.IsDone()
+			close(pchan)
+
+			if count.Load() != int64(its) || j.CompletedCount() != int64(its) {
+				So(count.Load(), ShouldEqual, its)
+				So(j.CompletedCount(), ShouldEqual, its)
+				break
+			}
+		}
+		// Polling for 4 idle 10ms intervals would take at least 40ms a Job.
+		So(time.Since(start), ShouldBeLessThan, time.Duration(jobs)*40*time.Millisecond/4)
+	})
+}
+
+func Test_JobIsDoneTimeout(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	// once returns whether exactly one value is received from b.
+	once := func(b <-chan bool) (value, ok bool) {
+		value = <-b
+		select {
+		case <-b:
+			return value, false
+		case <-time.After(20 * time.Millisecond):
+			return value, true
+		}
+	}
+
+	Convey("When a Job is done in time, IsDoneTimeout sends true, once.", t, func() {
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(nil)
+		done()
+
+		value, ok := once(j.IsDoneTimeout(time.Second))
+		So(value, ShouldBeTrue)
+		So(ok, ShouldBeTrue)
+	})
+
+	Convey("When a Job has a wedged worker, IsDoneTimeout sends false, once.", t, func() {
+		release := make(chan struct{})
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			<-release
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(nil)
+		done()
+
+		start := time.Now()
+		value, ok := once(j.IsDoneTimeout(50 * time.Millisecond))
+		So(value, ShouldBeFalse)
+		So(ok, ShouldBeTrue)
+		So(time.Since(start), ShouldBeLessThan, time.Second)
+
+		close(release)
+		<-j.IsDone()
+	})
+}
+
+func Test_JobCompleted(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 30
+
+	Convey("When N units of Work succeed, Completed is N, while failures only count toward CompletedCount.", t, func() {
+		j := NewJobErr(func(id any, work Work) error {
+			if work.GetBool("fail") {
+				return errors.New("failed")
+			}
+			return nil
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(4, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		wchan <- NewWork(map[string]any{"fail": true})
+		done()
+		<-j.IsDone()
+
+		So(j.Completed(), ShouldEqual, its)
+		So(j.CompletedCount(), ShouldEqual, its+1)
+	})
+}
+
+func Test_JobStats(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 50
+	maxWorkers := 4
+
+	Convey("When Stats are polled while a Job runs, the workers never exceed maxWorkers, and all of the Work completes.", t, func(c C) {
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			time.Sleep(time.Millisecond)
+		})
+		So(j.Stats(), ShouldResemble, JobStats{})
+
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(maxWorkers, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		stop := make(chan struct{})
+		polled := make(chan struct{})
+		go func() {
+			defer close(polled)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				stats := j.Stats()
+				c.So(stats.ActiveWorkers, ShouldBeLessThanOrEqualTo, maxWorkers)
+				time.Sleep(100 * time.Microsecond)
+			}
+		}()
+
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		So(j.Stats().Done, ShouldBeFalse)
+		done()
+		<-j.IsDone()
+		close(stop)
+		<-polled
+
+		stats := j.Stats()
+		So(stats.CompletedCount, ShouldEqual, its)
+		So(stats.ActiveWorkers, ShouldEqual, 0)
+		So(stats.Done, ShouldBeTrue)
+	})
+}
+
+func Test_JobBadMaxWorkers(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a Job is Supervised with zero or negative maxWorkers, it fails immediately and clearly, rather than hanging.", t, func() {
+		for _, maxWorkers := range []int{0, -1} {
+			j := NewJob(func(id any, work Work, pchan chan<- Progress) {})
+			So(func() { j.Supervisor(maxWorkers, make(chan Work)) }, ShouldPanicWith,
+				fmt.Sprintf("racket: Supervisor called with a maxWorkers of %d, less than 1", maxWorkers))
+		}
+		So(func() { NewQueuedJob(NewJob(func(id any, work Work, pchan chan<- Progress) {}), 0, 1, OverflowBlock) }, ShouldPanic)
+	})
+}
+
+func Test_JobSupervisedTwice(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	Convey("When a running Job is Supervised again, it panics, and the first Supervisor carries on.", t, func() {
+		var wCount atomic.Int64
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			wCount.Add(1)
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		So(func() { j.Supervisor(2, make(chan Work)) }, ShouldPanicWith, alreadySupervised)
+		So(func() { j.SupervisorPriority(2) }, ShouldPanicWith, alreadySupervised)
+
+		wchan <- NewWork(nil)
+		done()
+		<-j.IsDone()
+		close(pchan)
+		So(wCount.Load(), ShouldEqual, 1)
+
+		Convey("... but once it is done and Reset, it may be Supervised again.", func() {
+			So(func() { j.Supervisor(2, wchan) }, ShouldPanicWith, alreadySupervised)
+			So(j.Reset(), ShouldBeNil)
+
+			pchan, done := j.Supervisor(2, wchan)
+			go ProgressLogger(disco, false, nil, pchan, nil)
+			wchan <- NewWork(nil)
+			done()
+			<-j.IsDone()
+			close(pchan)
+			So(j.CompletedCount(), ShouldEqual, 1)
+		})
+	})
+}
+
+func Test_JobNilWorkerFunc(t *testing.T) {
+	Convey("When a Job is created with a nil WorkerFunc, it fails immediately and clearly.", t, func() {
+		So(func() { NewJob(nil) }, ShouldPanicWith, "racket: NewJob called with a nil WorkerFunc")
+		So(func() { NewJobContext(nil) }, ShouldPanicWith, "racket: NewJobContext called with a nil WorkerFuncContext")
+		So(func() { NewJobErr(nil) }, ShouldPanicWith, "racket: NewJobErr called with a nil WorkerFuncErr")
+		So(func() { NewJobAttempt(nil) }, ShouldPanicWith, "racket: NewJobAttempt called with a nil WorkerFuncAttempt")
+		So(func() { NewJobRetry(nil, 1, nil) }, ShouldPanicWith, "racket: NewJobRetry called with a nil WorkerFuncErr")
+		So(func() { NewJobTimeout(nil, time.Second) }, ShouldPanicWith, "racket: NewJobTimeout called with a nil WorkerFunc")
+		So(func() { NewInlineJob(nil, nil) }, ShouldPanicWith, "racket: NewInlineJob called with a nil WorkerFunc")
+	})
+}
+
+func Test_JobItemTimeout(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a WorkerFunc exceeds its item timeout, a ProgressTimeout is sent and the Job completes.", t, func(c C) {
+		var wCount atomic.Int64
+
+		wf := func(id any, work Work, pchan chan<- Progress) {
+			time.Sleep(time.Duration(work.GetInt("sleep")) * time.Millisecond)
+			wCount.Add(1)
+		}
+
+		j := NewJob(wf, WithItemTimeout(50*time.Millisecond))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+
+		var types []ProgressType
+		consumed := make(chan struct{})
+		go func() {
+			defer close(consumed)
+			for p := range pchan {
+				types = append(types, p.Type)
+				// Either of the idle workers may get the Work.
+				c.So(p.Error().Error(), ShouldBeIn, []string{"worker 0 timed out after 50ms", "worker 1 timed out after 50ms"})
+			}
+		}()
+
+		start := time.Now()
+		wchan <- NewWork(map[string]any{"sleep": 300})
+		done()
+		<-j.IsDone()
+
+		c.So(time.Since(start), ShouldBeLessThan, 300*time.Millisecond)
+		c.So(wCount.Load(), ShouldEqual, 0)
+
+		time.Sleep(300 * time.Millisecond) // let the abandoned worker finish
+		c.So(wCount.Load(), ShouldEqual, 1)
+		close(pchan)
+		<-consumed
+		c.So(types, ShouldResemble, []ProgressType{ProgressTimeout})
+	})
+
+	Convey("When a NewJobTimeout WorkerFunc hangs, its error is sent and the rest of the Work is still done.", t, func(c C) {
+		var wCount atomic.Int64
+		hang := make(chan struct{})
+
+		j := NewJobTimeout(func(id any, work Work, pchan chan<- Progress) {
+			if work.GetBool("hang") {
+				<-hang
+			}
+			wCount.Add(1)
+		}, 50*time.Millisecond)
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+
+		var errs []error
+		consumed := make(chan struct{})
+		go func() {
+			defer close(consumed)
+			for p := range pchan {
+				if err := p.Error(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}()
+
+		wchan <- NewWork(nil)
+		wchan <- NewWork(map[string]any{"hang": true})
+		wchan <- NewWork(nil)
+		done()
+		<-j.IsDone()
+
+		c.So(j.CompletedCount(), ShouldEqual, 3)
+		c.So(wCount.Load(), ShouldEqual, 2)
+
+		close(hang) // let the abandoned goroutine go
+		c.So(waitFor(time.Second, func() bool { return wCount.Load() == 3 }), ShouldBeTrue)
+		close(pchan)
+		<-consumed
+		c.So(errs, ShouldHaveLength, 1)
+		c.So(isTimeout(errs[0]), ShouldBeTrue)
+
+		c.So(func() { NewJobTimeout(func(id any, work Work, pchan chan<- Progress) {}, 0) }, ShouldPanicWith,
+			"racket: NewJobTimeout called with a perItem of 0s, not positive")
+	})
+
+	Convey("When an abandoned WorkerFunc sends Progress late under WithBufferedProgress, it is delivered before IsDone.", t, func(c C) {
+		late := make(chan struct{})
+
+		j := NewJobTimeout(func(id any, work Work, pchan chan<- Progress) {
+			<-late
+			pchan <- PMessagef("late")
+		}, 20*time.Millisecond, WithBufferedProgress(0, OverflowBlock))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+
+		var types []ProgressType
+		consumed := make(chan struct{})
+		go func() {
+			defer close(consumed)
+			for p := range pchan {
+				types = append(types, p.Type)
+			}
+		}()
+
+		wchan <- NewWork(nil)
+		done()
+		c.So(<-j.IsDoneTimeout(100*time.Millisecond), ShouldBeFalse) // the abandoned goroutine is still running
+
+		close(late)
+		<-j.IsDone()
+		close(pchan)
+		<-consumed
+		c.So(types, ShouldResemble, []ProgressType{ProgressTimeout, ProgressMessage})
+	})
+}
+
+func Test_JobReset(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	Convey("When a Job is Reset between batches, each batch runs correctly.", t, func(c C) {
+		var wCount atomic.Int64
+
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			wCount.Add(1)
+		}, WithCompletionLog(), WithProgressBuffer(4))
+		c.So(j.Reset(), ShouldBeNil) // never run, no problem
+
+		for _, its := range []int{10, 25} {
+			wCount.Store(0)
+
+			wchan := make(chan Work)
+			pchan, done := j.Supervisor(2, wchan)
+			go ProgressLogger(disco, false, nil, pchan, nil)
+
+			c.So(j.Reset(), ShouldEqual, ErrJobRunning)
+
+			for range its {
+				wchan <- NewWork(nil)
+			}
+			done()
+			<-j.IsDone()
+
+			c.So(wCount.Load(), ShouldEqual, its)
+			c.So(j.(JobDiagnostics).CompletionLog(), ShouldHaveLength, its)
+
+			c.So(j.Reset(), ShouldBeNil)
+			c.So(j.(JobDiagnostics).CompletionLog(), ShouldBeEmpty)
+			close(pchan)
+		}
+	})
+}
+
+func Test_JobDoneAllowRetries(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	backoff := func(int) time.Duration { return 100 * time.Millisecond }
+
+	// flaky fails the first two attempts, signaling firstFailed on the first.
+	flaky := func(attempts *atomic.Int64, firstFailed chan struct{}) WorkerFuncErr {
+		return func(id any, work Work) error {
+			switch attempts.Add(1) {
+			case 1:
+				close(firstFailed)
+				return errors.New("flaky")
+			case 2:
+				return errors.New("flaky")
+			}
+			return nil
+		}
+	}
+
+	Convey("When DoneAllowRetries is called while Work is mid-retry, it gets its remaining attempts.", t, func(c C) {
+		var (
+			attempts    atomic.Int64
+			eCount      atomic.Int64
+			firstFailed = make(chan struct{})
+		)
+
+		j := NewJobErr(flaky(&attempts, firstFailed), WithRetry(3, backoff))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, func(error) { eCount.Add(1) }, pchan, nil)
+
+		wchan <- NewWork(nil)
+		<-firstFailed
+		j.DoneAllowRetries()
+		<-j.IsDone()
+		done()
+
+		c.So(attempts.Load(), ShouldEqual, 3)
+		c.So(eCount.Load(), ShouldEqual, 0)
+	})
+
+	Convey("When the doneFunc is called while Work is mid-retry, it is cut off after the current attempt.", t, func(c C) {
+		var (
+			attempts    atomic.Int64
+			eCount      atomic.Int64
+			firstFailed = make(chan struct{})
+		)
+
+		j := NewJobErr(flaky(&attempts, firstFailed), WithRetry(3, backoff))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, func(error) { eCount.Add(1) }, pchan, nil)
+
+		wchan <- NewWork(nil)
+		<-firstFailed
+		done()
+		<-j.IsDone()
+
+		c.So(attempts.Load(), ShouldEqual, 1)
+		// IsDone doesn't wait for the consumer, so the last errf may still be running.
+		c.So(waitFor(time.Second, func() bool { return eCount.Load() == 1 }), ShouldBeTrue)
+	})
+}
+
+func Test_JobRetry(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	// run sends the Work through a single-worker Job, returning the Progress types sent.
+	run := func(j Job, work Work) []ProgressType {
+		wchan := make(chan Work)
+		pchan, _ := j.Supervisor(1, wchan)
+
+		var types []ProgressType
+		consumed := make(chan struct{})
+		go func() {
+			defer close(consumed)
+			for p := range pchan {
+				types = append(types, p.Type)
+			}
+		}()
+
+		wchan <- work
+		j.DoneAllowRetries()
+		<-j.IsDone()
+		close(pchan)
+		<-consumed
+		return types
+	}
+
+	Convey("When a NewJobRetry WorkerFuncErr fails once, it succeeds on the second attempt, with the same Work.", t, func() {
+		var names []string
+		j := NewJobRetry(func(id any, work Work) error {
+			names = append(names, work.GetString("name"))
+			if len(names) == 1 {
+				return errors.New("flaky")
+			}
+			return nil
+		}, 3, nil)
+
+		So(run(j, NewWork(map[string]any{"name": "hello"})), ShouldResemble, []ProgressType{ProgressMessage})
+		So(names, ShouldResemble, []string{"hello", "hello"})
+		So(j.Completed(), ShouldEqual, 1)
+	})
+
+	Convey("When a NewJobRetry WorkerFuncErr always fails, it exhausts its attempts, and only then errors.", t, func() {
+		var attempts int
+		j := NewJobRetry(func(id any, work Work) error {
+			attempts++
+			return errors.New("down")
+		}, 3, nil)
+
+		So(run(j, NewWork(nil)), ShouldResemble, []ProgressType{ProgressMessage, ProgressMessage, ProgressError})
+		So(attempts, ShouldEqual, 3)
+		So(j.Errors(), ShouldHaveLength, 1)
+	})
+
+	Convey("When a retried WorkerFunc always panics, only the final panic is sent as a ProgressError.", t, func() {
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			panic("down")
+		}, WithRetry(3, nil))
+
+		So(run(j, NewWork(nil)), ShouldResemble, []ProgressType{ProgressMessage, ProgressMessage, ProgressError})
+		So(j.Errors(), ShouldHaveLength, 1)
+	})
+
+	Convey("When a retried WorkerFunc always times out, only the final timeout is sent as a ProgressTimeout.", t, func() {
+		j := NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+			<-ctx.Done()
+		}, WithRetry(3, nil), WithItemTimeout(10*time.Millisecond))
+
+		So(run(j, NewWork(nil)), ShouldResemble, []ProgressType{ProgressMessage, ProgressMessage, ProgressTimeout})
+		So(j.Errors(), ShouldHaveLength, 1)
+		So(isTimeout(j.Errors()[0]), ShouldBeTrue)
+	})
+
+	Convey("When a NewJobRetry has a linear backoff, the attempts are spaced out by it.", t, func() {
+		var at []time.Time
+		j := NewJobRetry(func(id any, work Work) error {
+			at = append(at, time.Now())
+			return errors.New("down")
+		}, 4, func(attempt int) time.Duration { return time.Duration(attempt) * 20 * time.Millisecond })
+
+		run(j, NewWork(nil))
+		So(at, ShouldHaveLength, 4)
+		for i := 1; i < len(at); i++ {
+			want := time.Duration(i) * 20 * time.Millisecond
+			So(at[i].Sub(at[i-1]), ShouldBeBetween, want, want+50*time.Millisecond)
+		}
+	})
+
+	Convey("When a NewJobRetry has less than 1 attempt, it panics.", t, func() {
+		So(func() { NewJobRetry(func(id any, work Work) error { return nil }, 0, nil) }, ShouldPanicWith,
+			"racket: NewJobRetry called with 0 attempts, less than 1")
+	})
+}
+
+func Test_JobRateLimit(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 10
+	maxWorkers := 5
+	interval := 20 * time.Millisecond
+
+	// run sends its Work through a Job, returning how long it took.
+	run := func(opts ...JobOption) time.Duration {
+		start := time.Now() // the bucket refills from when it is made
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {}, opts...)
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(maxWorkers, wchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		done()
+		<-j.IsDone()
+		close(pchan)
+		return time.Since(start)
+	}
+
+	Convey("When a Job has a rate limit, the Work is paced to it, however many workers there are.", t, func() {
+		// The bucket starts with a token, so the first is free.
+		So(run(WithRateLimit(float64(time.Second/interval))), ShouldBeGreaterThanOrEqualTo, time.Duration(its-1)*interval)
+
+		Convey("... and without it, the Work is done at full speed.", func() {
+			So(run(), ShouldBeLessThan, time.Duration(its-1)*interval/2)
+		})
+	})
+	Convey("When a Job is given a rate limit that isn't positive, it panics.", t, func() {
+		So(func() { WithRateLimit(0) }, ShouldPanicWith, "racket: WithRateLimit called with a perSecond of 0, not positive")
+		So(func() { WithRateLimit(math.NaN()) }, ShouldPanicWith, "racket: WithRateLimit called with a perSecond of NaN, not positive")
+	})
+}
+
+func Test_JobPerWorkerRate(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 10
+	maxWorkers := 2
+	interval := 50 * time.Millisecond
+
+	Convey("When a Job has a per-worker rate, each worker respects its own rate independently.", t, func(c C) {
+		var (
+			lock   sync.Mutex
+			starts = make(map[any][]time.Time)
+		)
+
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			lock.Lock()
+			defer lock.Unlock()
+			starts[id] = append(starts[id], time.Now())
+		}, WithPerWorkerRate(float64(time.Second/interval)))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(maxWorkers, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		start := time.Now()
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		done()
+		<-j.IsDone()
+
+		// A shared rate would take (its-1)*interval
+		c.So(time.Since(start), ShouldBeLessThan, time.Duration(its-1)*interval)
+
+		lock.Lock()
+		defer lock.Unlock()
+		for _, ts := range starts {
+			for i := 1; i < len(ts); i++ {
+				// A late wake-up shortens the gap to the next start, which is on schedule, so allow for some jitter.
+				c.So(ts[i].Sub(ts[i-1]), ShouldBeGreaterThanOrEqualTo, interval-20*time.Millisecond)
+			}
+		}
+	})
+	Convey("When a Job is given a per-worker rate that isn't positive, it panics.", t, func() {
+		So(func() { WithPerWorkerRate(-1) }, ShouldPanicWith, "racket: WithPerWorkerRate called with a perSecond of -1, not positive")
+	})
+}
+
+func Test_JobRetryBudget(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 10
+	perSecond := 100.0
+
+	Convey("When every item fails at once, a Job with a retry budget keeps the retry rate within budget.", t, func(c C) {
+		var (
+			lock    sync.Mutex
+			calls   = make(map[int]int)
+			retries []time.Time
+		)
+
+		j := NewJobErr(func(id any, work Work) error {
+			lock.Lock()
+			defer lock.Unlock()
+			n := work.GetInt("n")
+			calls[n]++
+			if calls[n] > 1 {
+				retries = append(retries, time.Now())
+			}
+			return errors.New("outage")
+		}, WithRetry(2, nil), WithRetryBudget(perSecond))
+		wchan := make(chan Work)
+		pchan, _ := j.Supervisor(its, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for i := range its {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+		j.DoneAllowRetries()
+		<-j.IsDone()
+
+		lock.Lock()
+		defer lock.Unlock()
+		c.So(retries, ShouldHaveLength, its)
+		sort.Slice(retries, func(a, b int) bool { return retries[a].Before(retries[b]) })
+		// its retries at perSecond, with a burst of 1, can't take less than (its-1)/perSecond
+		minSpan := time.Duration(float64(its-1) / perSecond * float64(time.Second))
+		c.So(retries[len(retries)-1].Sub(retries[0]), ShouldBeGreaterThanOrEqualTo, minSpan-10*time.Millisecond)
+	})
+	Convey("When a Job is given a retry budget that isn't positive, it panics.", t, func() {
+		So(func() { WithRetryBudget(0) }, ShouldPanicWith, "racket: WithRetryBudget called with a perSecond of 0, not positive")
+	})
+}
+
+func Test_JobSingleWorkerFIFO(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 500
+
+	Convey("When a Job has a single worker, Work is processed strictly in the order it was sent.", t, func() {
+		var seen []int // only one worker at a time, and IsDone is a barrier
+
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			seen = append(seen, work.GetInt("n"))
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for i := range its {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+		done()
+		<-j.IsDone()
+
+		expected := make([]int, its)
+		for i := range expected {
+			expected[i] = i
+		}
+		So(seen, ShouldResemble, expected)
+	})
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from its stack header.
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	return strings.Fields(string(buf))[1] // "goroutine 42 [running]:..."
+}
+
+// runChurn sends its units of Work through a Job with maxWorkers, returning how many distinct goroutines ran them.
+func runChurn(its, maxWorkers int, opts ...JobOption) int {
+	disco := log.New(io.Discard, "", 0)
+	var (
+		lock sync.Mutex
+		ids  = make(map[string]struct{})
+	)
+
+	j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+		gid := goroutineID()
+		lock.Lock()
+		defer lock.Unlock()
+		ids[gid] = struct{}{}
+	}, opts...)
+	wchan := make(chan Work)
+	pchan, done := j.Supervisor(maxWorkers, wchan)
+	defer close(pchan)
+	go ProgressLogger(disco, false, nil, pchan, nil)
+
+	for range its {
+		wchan <- NewWork(nil)
+	}
+	done()
+	<-j.IsDone()
+
+	lock.Lock()
+	defer lock.Unlock()
+	return len(ids)
+}
+
+func Test_JobLifecycleHooks(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 20
+	maxWorkers := 3
+
+	Convey("When a Job has lifecycle hooks, each worker spawned is started and ended once.", t, func() {
+		var (
+			starts atomic.Int64
+			ends   atomic.Int64
+		)
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {},
+			WithOnStart(func(any) { starts.Add(1) }), WithOnEnd(func(any) { ends.Add(1) }))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(maxWorkers, wchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		done()
+		<-j.IsDone()
+		close(pchan)
+
+		// A worker per unit of Work, and any idle ones that were waiting when done was signaled.
+		So(starts.Load(), ShouldBeGreaterThanOrEqualTo, its)
+		So(ends.Load(), ShouldEqual, starts.Load())
+	})
+
+	Convey("When a Job with lifecycle hooks is done before its workers get any Work, they are still ended.", t, func() {
+		for _, opts := range [][]JobOption{nil, {WithPooledWorkers()}} {
+			var (
+				lock   sync.Mutex
+				starts = make(map[any]int)
+				ends   = make(map[any]int)
+			)
+			j := NewJob(func(id any, work Work, pchan chan<- Progress) {}, append(opts,
+				WithOnStart(func(id any) {
+					lock.Lock()
+					defer lock.Unlock()
+					starts[id]++
+				}),
+				WithOnEnd(func(id any) {
+					lock.Lock()
+					defer lock.Unlock()
+					ends[id]++
+				}))...)
+			pchan, done := j.Supervisor(maxWorkers, make(chan Work))
+			go ProgressLogger(disco, false, nil, pchan, nil)
+
+			So(waitFor(time.Second, func() bool {
+				lock.Lock()
+				defer lock.Unlock()
+				return len(starts) == maxWorkers
+			}), ShouldBeTrue)
+			done()
+			<-j.IsDone()
+			close(pchan)
+
+			So(ends, ShouldContainKey, 0)
+			So(ends, ShouldContainKey, 1)
+			So(ends, ShouldContainKey, 2)
+			So(starts, ShouldResemble, ends)
+		}
+	})
+}
+
+func Test_JobPooledWorkers(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	its := 100
+	maxWorkers := 3
+
+	Convey("When a Job has pooled workers, a fixed set of goroutines does all of the Work.", t, func() {
+		So(runChurn(its, maxWorkers, WithPooledWorkers()), ShouldBeLessThanOrEqualTo, maxWorkers)
+	})
+
+	Convey("When a Job doesn't have pooled workers, each unit of Work gets a fresh goroutine.", t, func() {
+		So(runChurn(its, maxWorkers), ShouldBeGreaterThan, maxWorkers)
+	})
+}
+
+func benchmarkChurn(b *testing.B, opts ...JobOption) {
+	its := 1000
+	maxWorkers := 4
+
+	var goroutines int
+	for b.Loop() {
+		goroutines += runChurn(its, maxWorkers, opts...)
+	}
+	b.ReportMetric(float64(goroutines)/float64(b.N*its), "goroutines/work")
+}
+
+func Benchmark_JobChurn(b *testing.B) {
+	benchmarkChurn(b)
+}
+
+func Benchmark_JobChurnPooled(b *testing.B) {
+	benchmarkChurn(b, WithPooledWorkers())
+}
+
+func Test_JobSkippedProgress(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	its := 3
+
+	Convey("When Work is skipped because the Job was cancelled, a ProgressSkipped is sent with the reason.", t, func(c C) {
+		var ran atomic.Int64
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			ran.Add(1)
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+		defer close(pchan)
+
+		var (
+			lock    sync.Mutex
+			reasons []string
+		)
+		go func() {
+			for p := range pchan {
+				if p.Type == ProgressSkipped {
+					lock.Lock()
+					reasons = append(reasons, p.Data.(string))
+					lock.Unlock()
+				}
+			}
+		}()
+
+		j.(*defaultJob).cancel()
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		done()
+		<-j.IsDone()
+
+		// IsDone doesn't wait for the consumer, so the last skip may still be in flight.
+		c.So(waitFor(time.Second, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+			return len(reasons) == its
+		}), ShouldBeTrue)
+
+		lock.Lock()
+		defer lock.Unlock()
+		c.So(ran.Load(), ShouldBeZeroValue)
+		c.So(reasons, ShouldResemble, []string{SkipCancelled, SkipCancelled, SkipCancelled})
+	})
+}
+
+func Test_JobCompletedCount(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	its := 20
+	maxWorkers := 4
+
+	Convey("When a WorkerFunc sends no Progress at all, the Job still completes and counts the Work.", t, func() {
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			// Nothing to say.
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(maxWorkers, wchan)
+		defer close(pchan)
+
+		var sent atomic.Int64
+		go func() {
+			for range pchan {
+				sent.Add(1)
+			}
+		}()
+
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		done()
+		<-j.IsDone()
+
+		So(j.CompletedCount(), ShouldEqual, its)
+		So(sent.Load(), ShouldBeZeroValue)
+
+		Convey("... and Reset clears the count", func() {
+			So(j.Reset(), ShouldBeNil)
+			So(j.CompletedCount(), ShouldBeZeroValue)
+		})
+	})
+}
+
+// waitFor polls cond every millisecond until it's true, returning false if the timeout expires first.
+func waitFor(timeout time.Duration, cond func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+func Test_JobBufferedProgress(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	its := 10
+	sends := 100
+	maxWorkers := 4
+
+	chatty := func(id any, work Work, pchan chan<- Progress) {
+		for range sends {
+			pchan <- PUpdate(1)
+		}
+	}
+
+	Convey("When a Job has unbounded buffered progress, workers never block on a stalled consumer.", t, func() {
+		j := NewJob(chatty, WithBufferedProgress(0, OverflowBlock))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(maxWorkers, wchan)
+		defer close(pchan)
+
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		done()
+
+		// Nobody is reading pchan, yet all of the Work gets done.
+		So(waitFor(time.Second, func() bool { return j.CompletedCount() == int64(its) }), ShouldBeTrue)
+
+		// And then the backlog is delivered, in full, before IsDone.
+		tracker := NewProgressTracker()
+		isDone := j.IsDone()
+		for {
+			select {
+			case p := <-pchan:
+				tracker.Track(p)
+				continue
+			case <-isDone:
+			}
+			break
+		}
+		So(tracker.Total(), ShouldEqual, its*sends)
+	})
+
+	Convey("When a Job has bounded buffered progress that drops, workers never block, and the drops are warned about.", t, func() {
+		var buf syncBuffer
+		bound := 10
+
+		j := NewJob(chatty, WithBufferedProgress(bound, OverflowDrop), WithLogger(log.New(&buf, "", 0)))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(maxWorkers, wchan)
+		defer close(pchan)
+
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		done()
+
+		So(waitFor(time.Second, func() bool { return j.CompletedCount() == int64(its) }), ShouldBeTrue)
+
+		var received int
+		isDone := j.IsDone()
+		for {
+			select {
+			case <-pchan:
+				received++
+				continue
+			case <-isDone:
+			}
+			break
+		}
+		So(received, ShouldBeBetweenOrEqual, bound, bound+1) // the buffer, and perhaps one in hand
+		So(buf.String(), ShouldContainSubstring, "progress dropped")
+	})
+}
+
+func Test_JobLongestRunningWorker(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a worker is slow, it's reported as the longest running, and warned about.", t, func() {
+		var buf syncBuffer
+		gate := make(chan struct{})
+
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			if work.GetBool("slow") {
+				<-gate
+			}
+		}, WithWorkerWarnThreshold(20*time.Millisecond), WithLogger(log.New(&buf, "", 0)))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+		defer close(pchan)
+		go ProgressLogger(log.New(io.Discard, "", 0), false, nil, pchan, nil)
+
+		id, dur := j.(JobDiagnostics).LongestRunningWorker()
+		So(id, ShouldBeNil)
+		So(dur, ShouldBeZeroValue)
+
+		wchan <- NewWork(map[string]any{"slow": true})
+		for range 5 {
+			wchan <- NewWork(nil)
+		}
+
+		So(waitFor(time.Second, func() bool {
+			return strings.Contains(buf.String(), "has been running for over 20ms")
+		}), ShouldBeTrue)
+		id, dur = j.(JobDiagnostics).LongestRunningWorker()
+		So(id, ShouldNotBeNil)
+		So(dur, ShouldBeGreaterThanOrEqualTo, 20*time.Millisecond)
+		So(buf.String(), ShouldContainSubstring, fmt.Sprintf("worker %v has been", id))
+
+		close(gate)
+		done()
+		<-j.IsDone()
+
+		id, dur = j.(JobDiagnostics).LongestRunningWorker()
+		So(id, ShouldBeNil)
+		So(dur, ShouldBeZeroValue)
+	})
+}
+
+func Test_JobShutdownSummary(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	its := 6
+
+	Convey("When a Job with a summary is Shutdown, the final Progress is the summary, with correct totals.", t, func() {
+		j := NewJobErr(func(id any, work Work) error {
+			if work.GetInt("n")%2 == 1 {
+				return errors.New("odd")
+			}
+			return nil
+		}, WithSummary())
+		wchan := make(chan Work)
+		pchan, _ := j.Supervisor(2, wchan)
+
+		var all []Progress
+		collected := make(chan struct{})
+		go func() {
+			defer close(collected)
+			for p := range pchan {
+				all = append(all, p)
+			}
+		}()
+
+		for i := range its {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+		So(waitFor(time.Second, func() bool { return j.CompletedCount() == int64(its) }), ShouldBeTrue)
+
+		j.Shutdown()
+		close(pchan)
+		<-collected
+
+		So(all, ShouldNotBeEmpty)
+		last := all[len(all)-1]
+		So(last.Type, ShouldEqual, ProgressEvent)
+		e := last.Data.(Event)
+		So(e.Name, ShouldEqual, EventSummary)
+		summary := e.Data.(Summary)
+		So(summary.Completed, ShouldEqual, its)
+		So(summary.Succeeded, ShouldEqual, its/2)
+		So(summary.Failed, ShouldEqual, its/2)
+		So(summary.Duration, ShouldBeGreaterThan, 0)
+	})
+
+	Convey("When a Job that was never Supervised is Shutdown, nothing happens.", t, func() {
+		So(func() { NewJob(func(id any, work Work, pchan chan<- Progress) {}).Shutdown() }, ShouldNotPanic)
+	})
+}
+
+func Test_JobDrain(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 20
+
+	Convey("When a Job is Drained, the Work queued on its buffered work channel is all done.", t, func() {
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			time.Sleep(time.Millisecond)
+		})
+		wchan := make(chan Work, its)
+		pchan, _ := j.Supervisor(1, wchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		j.Drain()
+		j.Drain() // safe to call twice
+		<-j.IsDone()
+		close(pchan)
+
+		So(j.CompletedCount(), ShouldEqual, its)
+	})
+
+	Convey("When a Job is Shutdown instead, the queued Work is dropped, and only the in-flight Work was run.", t, func() {
+		var ran atomic.Int64
+		j := NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+			ran.Add(1)
+			<-ctx.Done()
+		})
+		wchan := make(chan Work, its)
+		pchan, _ := j.Supervisor(1, wchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for range its {
+			wchan <- NewWork(nil)
+		}
+		So(waitFor(time.Second, func() bool { return j.Stats().BusyWorkers == 1 }), ShouldBeTrue)
+		j.Shutdown()
+		<-j.IsDone()
+		close(pchan)
+
+		So(ran.Load(), ShouldEqual, 1)
+		So(j.Completed(), ShouldBeZeroValue)
+	})
+}
+
+func Test_JobShutdownCleanup(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	Convey("When a Job with workers holding resources is Shutdown, their registered cleanups are run.", t, func() {
+		var (
+			j       Job
+			cleaned atomic.Int64
+		)
+		j = NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+			j.RegisterCleanup(func() { cleaned.Add(1) })
+			<-ctx.Done()
+		})
+		wchan := make(chan Work)
+		pchan, _ := j.Supervisor(2, wchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(nil)
+		wchan <- NewWork(nil)
+		So(waitFor(time.Second, func() bool { return len(j.(JobDiagnostics).ActiveWorkers()) == 2 }), ShouldBeTrue)
+
+		j.Shutdown()
+		close(pchan)
+		So(cleaned.Load(), ShouldEqual, 2)
+	})
+
+	Convey("When a cleanup hangs, Shutdown gives up on it after the timeout, with a warning.", t, func() {
+		var (
+			j       Job
+			buf     syncBuffer
+			release = make(chan struct{})
+		)
+		defer close(release)
+
+		j = NewJob(func(id any, work Work, pchan chan<- Progress) {
+			j.RegisterCleanup(func() { <-release })
+		}, WithCleanupTimeout(20*time.Millisecond), WithLogger(log.New(&buf, "", 0)))
+		wchan := make(chan Work)
+		pchan, _ := j.Supervisor(1, wchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(nil)
+		So(waitFor(time.Second, func() bool { return j.CompletedCount() == 1 }), ShouldBeTrue)
+
+		start := time.Now()
+		j.Shutdown()
+		close(pchan)
+		So(time.Since(start), ShouldBeLessThan, time.Second)
+		So(buf.String(), ShouldContainSubstring, "1 cleanups still running after 20ms")
+	})
+}
+
+func Test_JobOwnedProgress(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	its := 20
+
+	Convey("When a Job owns its progress channel, the final Progress arrives, then the channel closes, then IsDone fires.", t, func() {
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			time.Sleep(time.Duration(work.GetInt("n")) * time.Millisecond)
+			pchan <- PMessagef("last from %d", work.GetInt("n"))
+		}, WithOwnedProgress())
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(4, wchan)
+
+		var messages []string
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for p := range pchan {
+				messages = append(messages, p.Data.(string))
+			}
+		}()
+
+		for i := range its {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+		done()
+		<-j.IsDone()
+
+		// A closed channel is always ready, so this doesn't depend on the consumer having noticed yet.
+		select {
+		case _, ok := <-pchan:
+			So(ok, ShouldBeFalse)
+		default:
+			So("the progress channel is still open after IsDone", ShouldBeEmpty)
+		}
+		<-closed
+		So(messages, ShouldHaveLength, its)
+	})
+
+	Convey("When a Job that owns its progress channel is Shutdown with a summary, the summary is the final Progress.", t, func() {
+		j := NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+			<-ctx.Done()
+			pchan <- PMessagef("bailed")
+		}, WithOwnedProgress(), WithSummary(), WithBufferedProgress(10, OverflowBlock))
+		wchan := make(chan Work)
+		pchan, _ := j.Supervisor(2, wchan)
+
+		var all []Progress
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for p := range pchan {
+				all = append(all, p)
+			}
+		}()
+
+		wchan <- NewWork(nil)
+		wchan <- NewWork(nil)
+		j.Shutdown()
+		<-closed
+
+		So(all, ShouldHaveLength, 3)
+		for _, p := range all[:2] {
+			// Work cancelled before it started is skipped.
+			So(p.Type, ShouldBeIn, []ProgressType{ProgressMessage, ProgressSkipped})
+		}
+		So(all[2].Type, ShouldEqual, ProgressEvent)
+		So(all[2].Data.(Event).Name, ShouldEqual, EventSummary)
+	})
+
+	Convey("When a Job that owns its progress channel abandons a timed-out WorkerFunc, its late Progress is delivered before the close.", t, func() {
+		late := make(chan struct{})
+		j := NewJobTimeout(func(id any, work Work, pchan chan<- Progress) {
+			<-late
+			pchan <- PMessagef("late")
+		}, 20*time.Millisecond, WithOwnedProgress())
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+
+		var types []ProgressType
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			for p := range pchan {
+				types = append(types, p.Type)
+			}
+		}()
+
+		wchan <- NewWork(nil)
+		done()
+		So(<-j.IsDoneTimeout(100*time.Millisecond), ShouldBeFalse) // the abandoned goroutine is still running
+
+		close(late)
+		<-j.IsDone()
+		<-closed
+		So(types, ShouldResemble, []ProgressType{ProgressTimeout, ProgressMessage})
+	})
+}
+
+func Test_JobSupervisorContext(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	Convey("When the context of a SupervisorContext is cancelled, no more workers are made, and IsDone resolves.", t, func() {
+		var started atomic.Int64
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		j := NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+			started.Add(1)
+			<-ctx.Done()
+		})
+		wchan := make(chan Work)
+		pchan, _ := j.SupervisorContext(ctx, 2, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(nil)
+		wchan <- NewWork(nil)
+		So(waitFor(time.Second, func() bool { return started.Load() == 2 }), ShouldBeTrue)
+
+		cancel()
+		select {
+		case <-j.IsDone():
+		case <-time.After(time.Second):
+			So("IsDone did not resolve after the context was cancelled", ShouldBeEmpty)
+		}
+
+		select {
+		case wchan <- NewWork(nil):
+			So("Work was received after the context was cancelled", ShouldBeEmpty)
+		case <-time.After(50 * time.Millisecond):
+		}
+		So(started.Load(), ShouldEqual, 2)
+	})
+
+	Convey("When a WorkerFuncContext is Supervised with a context, it gets the context's values, and bails promptly when it is cancelled.", t, func() {
+		type key struct{}
+		var (
+			values  = make(chan any, 3)
+			bailed  atomic.Int64
+			started atomic.Int64
+		)
+
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "request-42"))
+		defer cancel()
+
+		j := NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+			values <- ctx.Value(key{})
+			started.Add(1)
+			<-ctx.Done()
+			bailed.Add(1)
+		})
+		wchan := make(chan Work)
+		pchan, _ := j.SupervisorContext(ctx, 3, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for range 3 {
+			wchan <- NewWork(nil)
+		}
+		So(waitFor(time.Second, func() bool { return started.Load() == 3 }), ShouldBeTrue)
+
+		start := time.Now()
+		cancel()
+		<-j.IsDone()
+		So(time.Since(start), ShouldBeLessThan, 500*time.Millisecond)
+		So(bailed.Load(), ShouldEqual, 3)
+		for range 3 {
+			So(<-values, ShouldEqual, "request-42")
+		}
+	})
+
+	Convey("When SupervisorContext has a maxWorkers less than 1, it panics.", t, func() {
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {})
+		So(func() { j.SupervisorContext(context.Background(), 0, make(chan Work)) }, ShouldPanicWith,
+			"racket: SupervisorContext called with a maxWorkers of 0, less than 1")
+	})
+}
+
+func Test_JobStopChannel(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	Convey("When a Job's stop channel is closed mid-run, it shuts down promptly and cleanly.", t, func() {
+		stop := make(chan struct{})
+		var (
+			started   atomic.Int64
+			cancelled atomic.Int64
+		)
+
+		j := NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+			started.Add(1)
+			select {
+			case <-ctx.Done():
+				cancelled.Add(1)
+			case <-time.After(10 * time.Second):
+			}
+		}, WithStopChannel(stop))
+		wchan := make(chan Work)
+		pchan, _ := j.Supervisor(2, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(nil)
+		wchan <- NewWork(nil)
+		So(waitFor(time.Second, func() bool { return started.Load() == 2 }), ShouldBeTrue)
+
+		start := time.Now()
+		close(stop)
+		select {
+		case <-j.IsDone():
+		case <-time.After(time.Second):
+			So("the Job didn't stop", ShouldBeEmpty)
+		}
+		So(time.Since(start), ShouldBeLessThan, time.Second)
+		So(cancelled.Load(), ShouldEqual, 2)
+	})
+}
+
+func Test_JobActiveWorkers(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	maxWorkers := 3
+
+	Convey("When workers are busy, ActiveWorkers reflects them, and empties after completion, even after panics.", t, func() {
+		gate := make(chan struct{})
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			<-gate
+			if work.GetBool("panic") {
+				panic("boom")
+			}
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(maxWorkers, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		So(j.(JobDiagnostics).ActiveWorkers(), ShouldBeEmpty)
+
+		before := time.Now()
+		for i := range maxWorkers {
+			wchan <- NewWork(map[string]any{"name": fmt.Sprintf("w%d", i), "panic": i == 0})
+		}
+		So(waitFor(time.Second, func() bool { return len(j.(JobDiagnostics).ActiveWorkers()) == maxWorkers }), ShouldBeTrue)
+
+		var names []string
+		for id, info := range j.(JobDiagnostics).ActiveWorkers() {
+			So(id, ShouldBeBetweenOrEqual, 0, maxWorkers-1)
+			So(info.Start, ShouldHappenOnOrAfter, before)
+			names = append(names, info.Work.GetString("name"))
+		}
+		sort.Strings(names)
+		So(names, ShouldResemble, []string{"w0", "w1", "w2"})
+
+		close(gate)
+		done()
+		<-j.IsDone()
+		So(j.(JobDiagnostics).ActiveWorkers(), ShouldBeEmpty)
+	})
+}
+
+func Test_JobCompletionGrace(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	grace := 100 * time.Millisecond
+
+	Convey("When a trickling producer has lulls shorter than the CompletionGrace, Done doesn't fire until it's idle for the grace.", t, func() {
+		var wCount atomic.Int64
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			wCount.Add(1)
+		}, CompletionGrace(grace))
+		wchan := make(chan Work)
+		pchan, _ := j.Supervisor(2, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		var last time.Time
+		isDone := j.IsDone()
+		for range 5 {
+			wchan <- NewWork(nil)
+			last = time.Now()
+			select {
+			case <-isDone:
+				So("Done fired during a lull", ShouldBeEmpty)
+			case <-time.After(grace / 2): // a lull
+			}
+		}
+
+		<-isDone
+		So(time.Since(last), ShouldBeGreaterThanOrEqualTo, grace)
+		So(wCount.Load(), ShouldEqual, 5)
+	})
+}
@@ -0,0 +1,300 @@
+package racket
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// PanicRecover is a PanicPolicy where a panicking WorkerFunc is recovered, reported as a ProgressError,
+// and the Job continues.
+// PanicAbort is a PanicPolicy where a panicking WorkerFunc is recovered, reported as a ProgressError,
+// and the Job is aborted: the shared context is cancelled, done is signaled, and the panic is available from Err().
+const (
+	PanicRecover PanicPolicy = iota
+	PanicAbort
+)
+
+// PanicPolicy is one of the constant policies for what a Job does when a WorkerFunc panics.
+type PanicPolicy int
+
+// JobOption is a functional option to tune the behavior of a Job created by NewJob or NewJobContext.
+type JobOption func(*defaultJob)
+
+// StopAfterSuccesses stops the Job once n units of Work have succeeded: the shared context is cancelled (so
+// cooperative WorkerFuncContext workers can bail) and done is signaled, so any remaining Work is not dispatched.
+// Useful for "fastest wins" or quorum scenarios. Work succeeds as its Result is a ResultSuccess: it finished
+// without error (after any retries), panic, or timeout, and without the Job being cancelled, or was a cache hit.
+func StopAfterSuccesses(n int) JobOption {
+	return func(j *defaultJob) {
+		j.stopAfter = int64(n)
+	}
+}
+
+// WithDefaults sets a default Work that is merged under each item of Work before it is dispatched to a
+// WorkerFunc: keys present in the item win, keys only present in the defaults are filled in.
+// Neither the defaults nor the submitted Work are modified.
+func WithDefaults(defaults Work) JobOption {
+	return func(j *defaultJob) {
+		j.defaults = defaults
+	}
+}
+
+// WithCompletionLog records a CompletionEntry for each unit of Work as it completes, accessible via
+// JobDiagnostics.CompletionLog(). This is a diagnostic, and is off by default for performance.
+func WithCompletionLog() JobOption {
+	return func(j *defaultJob) {
+		j.completionLog = true
+	}
+}
+
+// WithProgressBuffer makes the progress channel returned by Supervisor buffered, with size capacity.
+// If the buffer stays near-full, a rate-limited warning is emitted to the logger set via WithLogger,
+// as the progress consumer is too slow and workers will soon block sending Progress.
+func WithProgressBuffer(size int) JobOption {
+	return func(j *defaultJob) {
+		j.progressBuffer = size
+	}
+}
+
+// WithLogger sets a logger for the Job's internal warnings. By default they are discarded.
+func WithLogger(logger *log.Logger) JobOption {
+	return func(j *defaultJob) {
+		j.logger = logger
+	}
+}
+
+// WithPanicPolicy sets what the Job does when a WorkerFunc panics. The default is PanicRecover.
+func WithPanicPolicy(policy PanicPolicy) JobOption {
+	return func(j *defaultJob) {
+		j.panicPolicy = policy
+	}
+}
+
+// WithPanicStack adds the stack trace of the panicking goroutine to the error reported when a WorkerFunc panics.
+func WithPanicStack() JobOption {
+	return func(j *defaultJob) {
+		j.panicStack = true
+	}
+}
+
+// WithItemTimeout sets a deadline for each WorkerFunc invocation. The WorkerFunc is run in its own goroutine
+// and raced against the deadline; WorkerFuncContext workers also see it on their context. If the deadline expires,
+// a ProgressTimeout is sent (under WithRetry, only for the final attempt), and the worker moves on, so the Job can
+// finish. The abandoned goroutine may continue, and may still send Progress, so don't close the progress channel
+// until it is truly done. Under WithBufferedProgress or WithOwnedProgress, the Job waits for abandoned goroutines
+// to return before it closes a progress channel, so IsDone waits for them too, and a WorkerFunc that never
+// returns holds it up forever.
+func WithItemTimeout(timeout time.Duration) JobOption {
+	return func(j *defaultJob) {
+		j.itemTimeout = timeout
+	}
+}
+
+// WithRetry retries a failed WorkerFunc invocation (one that returned an error, panicked, or timed out) up to
+// attempts times in total, waiting backoff(attempt) between attempts. A ProgressMessage is sent for each retry,
+// and only the final failure is sent as a ProgressError (or a ProgressTimeout, if it timed out). The same Work is
+// passed to each attempt. A nil backoff retries immediately. Retries stop early if the shared context is
+// cancelled, or the doneFunc returned by Supervisor is called (see DoneAllowRetries).
+func WithRetry(attempts int, backoff func(attempt int) time.Duration) JobOption {
+	return func(j *defaultJob) {
+		j.retryAttempts = attempts
+		j.retryBackoff = backoff
+	}
+}
+
+// WithResults sends a Result to the results channel for every unit of Work a worker receives, once it is
+// finished with it. Sending blocks the worker until the Result is received. The caller is responsible for closing
+// the results channel after IsDone fires.
+func WithResults(results chan<- Result) JobOption {
+	return func(j *defaultJob) {
+		j.results = results
+	}
+}
+
+// WithPerWorkerRate gives each worker (by ID, i.e. concurrency slot) its own rate limiter, allowing it to call the
+// WorkerFunc at most perSecond times per second (including retries), independent of the other workers and of how
+// fast Work is dispatched.
+// It panics if perSecond isn't positive.
+func WithPerWorkerRate(perSecond float64) JobOption {
+	if !(perSecond > 0) { // NaN too
+		panic(fmt.Sprintf("racket: WithPerWorkerRate called with a perSecond of %g, not positive", perSecond))
+	}
+	return func(j *defaultJob) {
+		j.workerRates = newBucketSet(perSecond)
+	}
+}
+
+// WithRateLimit caps how often the WorkerFunc is called across the whole Job to perSecond times per second
+// (including retries), e.g. for a third-party API with a rate limit, however many workers there are. The workers
+// still run concurrently, but their starts are paced. See WithPerWorkerRate to limit each worker instead.
+// It panics if perSecond isn't positive.
+func WithRateLimit(perSecond float64) JobOption {
+	if !(perSecond > 0) { // NaN too
+		panic(fmt.Sprintf("racket: WithRateLimit called with a perSecond of %g, not positive", perSecond))
+	}
+	return func(j *defaultJob) {
+		j.rate = newTokenBucket(perSecond, 1)
+	}
+}
+
+// WithRetryBudget caps retries across the whole Job to perSecond per second, so a widespread failure doesn't
+// cause every item to retry at once. Retries beyond the budget are deferred (after any backoff) until the budget
+// allows them, or retries are stopped. Only meaningful with WithRetry.
+// It panics if perSecond isn't positive.
+func WithRetryBudget(perSecond float64) JobOption {
+	if !(perSecond > 0) { // NaN too
+		panic(fmt.Sprintf("racket: WithRetryBudget called with a perSecond of %g, not positive", perSecond))
+	}
+	return func(j *defaultJob) {
+		j.retryBudget = newTokenBucket(perSecond, 1)
+	}
+}
+
+// WithPooledWorkers runs a fixed pool of maxWorkers long-lived workers, started by Supervisor, that each loop
+// pulling Work until the Job is done, rather than the default of spawning a one-shot worker goroutine per unit
+// of Work as the lock allows. This bounds goroutine churn for Jobs with lots of small Work, at the cost of
+// keeping idle workers around.
+func WithPooledWorkers() JobOption {
+	return func(j *defaultJob) {
+		j.pooled = true
+	}
+}
+
+// WithIDAllocator has workers given IDs from the IDAllocator, rather than the default of their slot index
+// (0 to maxWorkers-1), e.g. a &SequentialIDs{}, or UUIDs for log correlation. Next is called once per worker
+// started. As WithPerWorkerRate limits each ID, it should be used with an IDAllocator that repeats IDs.
+func WithIDAllocator(ids IDAllocator) JobOption {
+	return func(j *defaultJob) {
+		j.ids = ids
+	}
+}
+
+// WithBufferedProgress has Progress sent by workers go into an internal buffer, drained to the progress channel
+// by a background goroutine, so a slow consumer never stalls a worker. If bound is greater than 0, at most bound
+// Progress are buffered, and when the buffer is full the policy decides: OverflowDrop discards the Progress (with
+// a warning to the logger when the Job is finished), and OverflowBlock blocks the worker. A bound of 0 or less is
+// unbounded, so memory grows with the backlog. IsDone waits for the buffer to be drained, and so for any
+// abandoned WithItemTimeout invocations, which may still send Progress, to return.
+func WithBufferedProgress(bound int, policy OverflowPolicy) JobOption {
+	return func(j *defaultJob) {
+		j.bufferProgress = true
+		j.progressBound = bound
+		j.progressPolicy = policy
+	}
+}
+
+// WithWorkerWarnThreshold logs a warning to the logger when a worker has been working on a unit of Work (including
+// retries) for longer than the threshold. Workers can't be killed, but this gives visibility into stuck ones.
+// See also JobDiagnostics.LongestRunningWorker.
+func WithWorkerWarnThreshold(threshold time.Duration) JobOption {
+	return func(j *defaultJob) {
+		j.warnAfter = threshold
+	}
+}
+
+// WithTracing starts a span from the Tracer around each unit of Work (including retries), passed to a
+// WorkerFuncContext in its context. If the Work has a serialized trace context in TraceKey, and propagator is
+// non-nil, the span is started as a child of that remote span context, linking the Work to the trace of the
+// request that originated it. Errors the Work finally fails with are recorded on the span.
+func WithTracing(tracer Tracer, propagator TracePropagator) JobOption {
+	return func(j *defaultJob) {
+		j.tracer = tracer
+		j.propagator = propagator
+	}
+}
+
+// WithResultBuffer buffers up to bound Results between the workers and the WithResults channel, so workers keep
+// running when Result consumption lags. The tradeoff is that when the buffer is full the oldest Result is dropped
+// to make room (see JobDiagnostics.ResultsDropped), so a lagging consumer sees the most recent Results, but not
+// all of them. IsDone waits for the buffer to be drained, so the consumer must keep consuming until then.
+func WithResultBuffer(bound int) JobOption {
+	return func(j *defaultJob) {
+		j.resultBound = bound
+	}
+}
+
+// WithSummary has Shutdown send a ProgressEvent named EventSummary, with a Summary of the counts and duration of
+// the run, as the final Progress.
+func WithSummary() JobOption {
+	return func(j *defaultJob) {
+		j.summary = true
+	}
+}
+
+// WithResultCache caches the values of successful Work, for idempotent but expensive WorkerFuncs, so that a repeat
+// of the Work reuses the cached value without running the WorkerFunc again: a ProgressSkipped with the reason
+// SkipCacheHit is sent, and the Result is a ResultSuccess with 0 Attempts. Unlike other skipped Work, a cache hit
+// counts as finished, and successful, in CompletedCount, Completed, and the Summary. Up to size values are cached,
+// least recently used first out, each for up to ttl if it is greater than 0. Work is keyed by the keyFunc, or if nil,
+// by equality of its JSON form (Work that can't be marshaled isn't cached).
+func WithResultCache(size int, ttl time.Duration, keyFunc func(Work) string) JobOption {
+	return func(j *defaultJob) {
+		j.cache = newResultCache(size, ttl, keyFunc)
+	}
+}
+
+// WithStopChannel hard-cancels the Job when the stop channel is closed, for callers with an existing stop
+// lifecycle rather than a context: dispatch stops, done is signaled (without retries), and the Job's context, as
+// handed to a WorkerFuncContext, is cancelled so cooperative workers can bail early.
+func WithStopChannel(stop <-chan struct{}) JobOption {
+	return func(j *defaultJob) {
+		j.stop = stop
+	}
+}
+
+// CompletionGrace signals done (allowing retries, as DoneAllowRetries) once the Job has been idle, with no Work
+// received and no worker busy, for the full grace period, so a trickling producer needn't call the doneFunc, and
+// IsDone won't fire during its lulls shorter than the grace. The producer must not send Work after that.
+func CompletionGrace(grace time.Duration) JobOption {
+	return func(j *defaultJob) {
+		j.grace = grace
+	}
+}
+
+// WithPriorityAging raises the effective priority of Work waiting in the SupervisorPriority queue by bumpBy for
+// every bumpEvery it waits (pro rata), so low Priority Work is eventually dispatched, even under a constant inflow
+// of higher Priority Work.
+func WithPriorityAging(bumpEvery time.Duration, bumpBy int) JobOption {
+	return func(j *defaultJob) {
+		j.agingEvery = bumpEvery
+		j.agingBy = bumpBy
+	}
+}
+
+// WithCleanupTimeout sets how long Shutdown waits for the cleanups registered by RegisterCleanup, before warning
+// and giving up on them. The default is 10 seconds.
+func WithCleanupTimeout(timeout time.Duration) JobOption {
+	return func(j *defaultJob) {
+		j.cleanupTimeout = timeout
+	}
+}
+
+// WithOwnedProgress makes the Job own the progress channel returned by Supervisor, so the consumer mustn't close it:
+// once all of the workers have finished, and all of their Progress has been delivered (see WithBufferedProgress),
+// the Job closes it, and only then does IsDone fire. So the consumer can simply range over the channel, and the
+// final Progress arrives before IsDone. With WithItemTimeout, the close also waits for abandoned WorkerFunc
+// invocations to return, as they may still send Progress. If the Job is Shutdown with WithSummary before it is done,
+// the Summary is the final Progress before the close.
+func WithOwnedProgress() JobOption {
+	return func(j *defaultJob) {
+		j.ownProgress = true
+	}
+}
+
+// WithOnStart calls onStart with the ID of each worker as it starts, before it waits for Work, e.g. to attach
+// a per-worker connection or span. By default, a worker is started per unit of Work (see WithPooledWorkers), and
+// onStart is called on the worker's goroutine, so a slow onStart holds up the Work.
+func WithOnStart(onStart func(id any)) JobOption {
+	return func(j *defaultJob) {
+		j.onStart = onStart
+	}
+}
+
+// WithOnEnd calls onEnd with the ID of each worker as it exits, whether it did any Work or not, e.g. when the
+// Job is done while it waits. It is paired with WithOnStart, to release what onStart acquired.
+func WithOnEnd(onEnd func(id any)) JobOption {
+	return func(j *defaultJob) {
+		j.onEnd = onEnd
+	}
+}
@@ -0,0 +1,82 @@
+package racket
+
+import "context"
+
+// NewOrderedJob consumes a func that returns a value of T to accomplish Work, and returns a ResultJob whose Results
+// are the values of successful Work in the order the Work was submitted, rather than the order it completed, e.g.
+// to map over a slice. Values that complete ahead of their turn are held until the Work before them is done (as
+// with NewCommitJob), so one slow unit of Work holds up the Results behind it, but not the workers. Failed Work
+// has no value, and doesn't hold up the Work behind it; its errors are in Errors. It panics if workerFunc is nil,
+// rather than at the first dispatch.
+func NewOrderedJob[T any](workerFunc func(id any, work Work) (T, error), opts ...JobOption) ResultJob[T] {
+	if workerFunc == nil {
+		panic("racket: NewOrderedJob called with a nil func")
+	}
+
+	j := newJob(func(_ context.Context, id any, work Work, _ chan<- Progress) (any, error) {
+		return workerFunc(id, work)
+	}, opts...)
+	return &orderedJob[T]{
+		defaultJob: j,
+		out:        make(chan T),
+	}
+}
+
+// orderedJob is a defaultJob that commits the values of its successful Results, in submission order, to out.
+type orderedJob[T any] struct {
+	*defaultJob
+	out chan T
+}
+
+// Results returns the channel of values.
+func (o *orderedJob[T]) Results() <-chan T {
+	return o.out
+}
+
+// Supervisor is defaultJob.Supervisor, also committing the values to Results.
+func (o *orderedJob[T]) Supervisor(maxWorkers int, workChan chan Work) (progressChan chan Progress, doneFunc func()) {
+	o.commitTo(o.out)
+	progressChan, doneFunc = o.defaultJob.Supervisor(maxWorkers, workChan)
+	go closeCommitted(o.commits.committed, o.out)
+	return progressChan, doneFunc
+}
+
+// SupervisorContext is defaultJob.SupervisorContext, also committing the values to Results.
+func (o *orderedJob[T]) SupervisorContext(ctx context.Context, maxWorkers int, workChan chan Work) (progressChan chan Progress, doneFunc func()) {
+	o.commitTo(o.out)
+	progressChan, doneFunc = o.defaultJob.SupervisorContext(ctx, maxWorkers, workChan)
+	go closeCommitted(o.commits.committed, o.out)
+	return progressChan, doneFunc
+}
+
+// SupervisorPriority is defaultJob.SupervisorPriority, also committing the values to Results. The submission order
+// is the order the Work is dispatched in, i.e. by priority.
+func (o *orderedJob[T]) SupervisorPriority(maxWorkers int) (submit func(PriorityWork), progressChan chan Progress, doneFunc func()) {
+	o.commitTo(o.out)
+	submit, progressChan, doneFunc = o.defaultJob.SupervisorPriority(maxWorkers)
+	go closeCommitted(o.commits.committed, o.out)
+	return submit, progressChan, doneFunc
+}
+
+// Reset is defaultJob.Reset, also replacing the closed Results channel.
+func (o *orderedJob[T]) Reset() error {
+	if err := o.defaultJob.Reset(); err != nil {
+		return err
+	}
+	o.out = make(chan T)
+	return nil
+}
+
+// commitTo has this run's values committed to out, which is bound now, so a late commit can't race a Reset.
+func (o *orderedJob[T]) commitTo(out chan T) {
+	o.commitFunc = func(_ int, value any) {
+		v, _ := value.(T) // a nil value of an interface T isn't a T
+		out <- v
+	}
+}
+
+// closeCommitted closes out once everything has been committed to it.
+func closeCommitted[T any](committed chan struct{}, out chan T) {
+	<-committed
+	close(out)
+}
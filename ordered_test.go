@@ -0,0 +1,156 @@
+package racket
+
+import (
+	"errors"
+	"io"
+	"log"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_OrderedJob(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 10
+
+	// payload is a value big enough to notice, with its index.
+	type payload struct {
+		n    int
+		junk [1 << 16]byte
+	}
+
+	Convey("When the Work of an OrderedJob finishes in reverse, the Results are still in submission order, and released once drained.", t, func() {
+		var (
+			lock      sync.Mutex
+			completed []int
+			released  atomic.Int64
+		)
+		finished := make([]chan struct{}, its)
+		for i := range finished {
+			finished[i] = make(chan struct{})
+		}
+
+		j := NewOrderedJob(func(id any, work Work) (*payload, error) {
+			if work.GetBool("again") {
+				return &payload{}, nil
+			}
+			n := work.GetInt("n")
+			defer close(finished[n])
+			if n+1 < its {
+				<-finished[n+1] // wait for the Work after this one
+			}
+
+			lock.Lock()
+			completed = append(completed, n)
+			lock.Unlock()
+			if n == 3 {
+				return nil, errors.New("unlucky")
+			}
+
+			p := &payload{n: n}
+			runtime.SetFinalizer(p, func(*payload) { released.Add(1) })
+			return p, nil
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(its, wchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		var order []int
+		collected := make(chan struct{})
+		go func() {
+			defer close(collected)
+			for p := range j.Results() {
+				order = append(order, p.n) // but not the payload
+			}
+		}()
+
+		for i := range its {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+		done()
+		<-j.IsDone()
+		<-collected
+		close(pchan)
+
+		expected := []int{}
+		reversed := []int{}
+		for i := range its {
+			reversed = append(reversed, its-1-i)
+			if i != 3 {
+				expected = append(expected, i)
+			}
+		}
+		So(completed, ShouldResemble, reversed)
+		So(order, ShouldResemble, expected)
+		So(j.Errors(), ShouldHaveLength, 1)
+
+		So(waitFor(5*time.Second, func() bool {
+			runtime.GC()
+			return released.Load() == int64(its-1)
+		}), ShouldBeTrue)
+
+		Convey("... and after a Reset, it can be run again.", func() {
+			So(j.Reset(), ShouldBeNil)
+			wchan := make(chan Work)
+			pchan, done := j.Supervisor(1, wchan)
+			go ProgressLogger(disco, false, nil, pchan, nil)
+
+			var count int
+			collected := make(chan struct{})
+			go func() {
+				defer close(collected)
+				for range j.Results() {
+					count++
+				}
+			}()
+
+			wchan <- NewWork(map[string]any{"again": true})
+			done()
+			<-j.IsDone()
+			<-collected
+			close(pchan)
+			So(count, ShouldEqual, 1)
+		})
+	})
+
+	Convey("When an OrderedJob of an interface type returns nil values, they are nil Results, in order.", t, func() {
+		j := NewOrderedJob(func(id any, work Work) (any, error) {
+			if n := work.GetInt("n"); n%2 == 0 {
+				return n, nil
+			}
+			return nil, nil
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		var got []any
+		collected := make(chan struct{})
+		go func() {
+			defer close(collected)
+			for v := range j.Results() {
+				got = append(got, v)
+			}
+		}()
+
+		for i := range 4 {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+		done()
+		<-j.IsDone()
+		<-collected
+		close(pchan)
+		So(got, ShouldResemble, []any{0, nil, 2, nil})
+	})
+
+	Convey("When an OrderedJob is created with a nil func, it panics.", t, func() {
+		So(func() { NewOrderedJob[int](nil) }, ShouldPanicWith, "racket: NewOrderedJob called with a nil func")
+	})
+}
@@ -0,0 +1,98 @@
+package racket
+
+import (
+	"sync/atomic"
+)
+
+// ProgressPauser forwards Progress, and can be paused (e.g. while a consumer reconfigures) without blocking
+// workers: while paused, Progress is buffered, up to a bound, and replayed in order on resume. What happens
+// when the buffer is full follows the OverflowPolicy: OverflowBlock stops receiving (so senders block),
+// OverflowDrop discards, and counts, the new Progress, and OverflowDropOldest discards, and counts, the oldest.
+type ProgressPauser struct {
+	bound   int
+	policy  OverflowPolicy
+	paused  atomic.Bool
+	dropped atomic.Int64
+	wake    chan struct{}
+}
+
+// NewProgressPauser returns a ProgressPauser that buffers up to bound Progress while paused, following the policy
+// when the buffer is full.
+func NewProgressPauser(bound int, policy OverflowPolicy) *ProgressPauser {
+	return &ProgressPauser{
+		bound:  max(bound, 1),
+		policy: policy,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// Pause suspends forwarding, buffering Progress instead.
+func (pp *ProgressPauser) Pause() {
+	pp.paused.Store(true)
+	pp.nudge()
+}
+
+// Resume replays any buffered Progress, and resumes forwarding.
+func (pp *ProgressPauser) Resume() {
+	pp.paused.Store(false)
+	pp.nudge()
+}
+
+// Dropped returns how many Progress have been dropped because the buffer was full, under OverflowDrop or OverflowDropOldest.
+func (pp *ProgressPauser) Dropped() int64 {
+	return pp.dropped.Load()
+}
+
+// Forward forwards every Progress from in to out, until in is closed and the buffer is empty. If in is
+// closed while paused, Forward waits for Resume to replay the buffer. out is not closed.
+func (pp *ProgressPauser) Forward(in <-chan Progress, out chan<- Progress) {
+	var buffer []Progress
+
+	for in != nil || len(buffer) > 0 {
+		var (
+			paused = pp.paused.Load()
+			recv   <-chan Progress
+			send   chan<- Progress
+			next   Progress
+		)
+
+		switch {
+		case in == nil:
+		case !paused && len(buffer) == 0, paused && len(buffer) < pp.bound:
+			recv = in
+		case paused && pp.policy != OverflowBlock:
+			recv = in // and drop one
+		}
+		if !paused && len(buffer) > 0 {
+			send = out
+			next = buffer[0]
+		}
+
+		select {
+		case p, ok := <-recv:
+			if !ok {
+				in = nil
+				continue
+			}
+			if len(buffer) >= pp.bound {
+				pp.dropped.Add(1)
+				if pp.policy != OverflowDropOldest {
+					continue
+				}
+				buffer = buffer[1:]
+			}
+			buffer = append(buffer, p)
+		case send <- next:
+			buffer = buffer[1:]
+		case <-pp.wake:
+		}
+	}
+}
+
+// nudge wakes Forward up to re-evaluate whether it is paused.
+func (pp *ProgressPauser) nudge() {
+	select {
+	case pp.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,101 @@
+package racket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ProgressPauser(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a ProgressPauser is paused with a Block policy, Progress is buffered without loss, and replayed on resume", t, func() {
+		in := make(chan Progress)
+		out := make(chan Progress)
+		pp := NewProgressPauser(10, OverflowBlock)
+
+		forwarded := make(chan struct{})
+		go func() {
+			defer close(forwarded)
+			pp.Forward(in, out)
+		}()
+
+		in <- PMessagef("before")
+		So(<-out, ShouldEqual, PMessagef("before"))
+
+		pp.Pause()
+		for i := range 5 {
+			in <- PUpdate(int64(i)) // does not block, even though nothing is reading out
+		}
+		close(in)
+
+		select {
+		case p := <-out:
+			So(p, ShouldBeNil) // should not have happened
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		pp.Resume()
+		for i := range 5 {
+			So(<-out, ShouldEqual, PUpdate(int64(i)))
+		}
+		<-forwarded
+		So(pp.Dropped(), ShouldEqual, 0)
+	})
+
+	Convey("When a ProgressPauser is paused with a Drop policy, Progress beyond the bound is dropped and counted", t, func() {
+		in := make(chan Progress)
+		out := make(chan Progress)
+		pp := NewProgressPauser(2, OverflowDrop)
+		pp.Pause()
+
+		forwarded := make(chan struct{})
+		go func() {
+			defer close(forwarded)
+			pp.Forward(in, out)
+		}()
+
+		for i := range 5 {
+			in <- PUpdate(int64(i))
+		}
+		close(in)
+		for pp.Dropped() < 3 {
+			time.Sleep(time.Millisecond)
+		}
+
+		pp.Resume()
+		So(<-out, ShouldEqual, PUpdate(0))
+		So(<-out, ShouldEqual, PUpdate(1))
+		<-forwarded
+		So(pp.Dropped(), ShouldEqual, 3)
+	})
+
+	Convey("When a ProgressPauser is paused with a DropOldest policy, the oldest Progress is dropped and counted", t, func() {
+		in := make(chan Progress)
+		out := make(chan Progress)
+		pp := NewProgressPauser(2, OverflowDropOldest)
+		pp.Pause()
+
+		forwarded := make(chan struct{})
+		go func() {
+			defer close(forwarded)
+			pp.Forward(in, out)
+		}()
+
+		for i := range 5 {
+			in <- PUpdate(int64(i))
+		}
+		close(in)
+		for pp.Dropped() < 3 {
+			time.Sleep(time.Millisecond)
+		}
+
+		pp.Resume()
+		So(<-out, ShouldEqual, PUpdate(3))
+		So(<-out, ShouldEqual, PUpdate(4))
+		<-forwarded
+		So(pp.Dropped(), ShouldEqual, 3)
+	})
+}
@@ -0,0 +1,143 @@
+package racket
+
+import (
+	"container/heap"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PriorityWork is Work with a Priority, for SupervisorPriority: higher Priority Work is dispatched first.
+type PriorityWork struct {
+	Work     Work
+	Priority int
+}
+
+// priorityItem is queued Work, with its effective priority as a key that doesn't change as it waits.
+type priorityItem struct {
+	work Work
+	key  float64
+	seq  uint64
+}
+
+// priorityHeap is a max-heap of priorityItems by key, and then FIFO by seq.
+type priorityHeap []priorityItem
+
+func (h priorityHeap) Len() int { return len(h) }
+func (h priorityHeap) Less(i, k int) bool {
+	if h[i].key != h[k].key {
+		return h[i].key > h[k].key
+	}
+	return h[i].seq < h[k].seq
+}
+func (h priorityHeap) Swap(i, k int) { h[i], h[k] = h[k], h[i] }
+func (h *priorityHeap) Push(x any)   { *h = append(*h, x.(priorityItem)) }
+func (h *priorityHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// priorityQueue is the queue of Work for SupervisorPriority, that workers pop from as they are ready.
+type priorityQueue struct {
+	lock      sync.Mutex
+	cond      *sync.Cond
+	heap      priorityHeap
+	seq       uint64
+	closed    bool // no more Work will be pushed
+	stopped   bool // the Job is done, no more Work will be popped
+	epoch     time.Time
+	bumpEvery time.Duration
+	bumpBy    int
+}
+
+// newPriorityQueue returns a priorityQueue, aging queued Work by bumpBy every bumpEvery, if bumpEvery is greater than 0.
+func newPriorityQueue(bumpEvery time.Duration, bumpBy int) *priorityQueue {
+	q := &priorityQueue{
+		epoch:     time.Now(),
+		bumpEvery: bumpEvery,
+		bumpBy:    bumpBy,
+	}
+	q.cond = sync.NewCond(&q.lock)
+	return q
+}
+
+// push queues the Work, unless the queue is closed.
+func (q *priorityQueue) push(pw PriorityWork) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return
+	}
+
+	key := float64(pw.Priority)
+	if q.bumpEvery > 0 {
+		// All queued Work ages at the same rate, so rather than re-ordering as time passes, Work pushed later
+		// is keyed as if it had already lost the bumps it missed. The order is the same either way.
+		key -= float64(q.bumpBy) * float64(time.Since(q.epoch)) / float64(q.bumpEvery)
+	}
+	q.seq++
+	heap.Push(&q.heap, priorityItem{work: pw.Work, key: key, seq: q.seq})
+	q.cond.Signal()
+}
+
+// pop waits for the highest priority Work, returning false if the queue is closed and empty, or stopped.
+func (q *priorityQueue) pop() (Work, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for len(q.heap) == 0 && !q.closed && !q.stopped {
+		q.cond.Wait()
+	}
+	if q.stopped || len(q.heap) == 0 {
+		return Work{}, false
+	}
+	return heap.Pop(&q.heap).(priorityItem).work, true
+}
+
+// close signals that no more Work will be pushed. Queued Work is still popped. It is safe to call more than once.
+func (q *priorityQueue) close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// stop wakes any waiting pops, and drops any queued Work.
+func (q *priorityQueue) stop() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.stopped = true
+	q.heap = nil
+	q.cond.Broadcast()
+}
+
+// SupervisorPriority is Supervisor, but rather than reading a work channel, Work is submitted to a queue that the
+// workers pull the highest Priority Work from as they become ready, in submission order among equals. The doneFunc
+// signals that no more Work will be submitted, and Work submitted after it is ignored, but the queued Work is still
+// dispatched before the Job is done. See WithPriorityAging to keep low Priority Work from starving.
+func (j *defaultJob) SupervisorPriority(maxWorkers int) (submit func(PriorityWork), progressChan chan Progress, doneFunc func()) {
+	if maxWorkers < 1 {
+		panic(fmt.Sprintf("racket: SupervisorPriority called with a maxWorkers of %d, less than 1", maxWorkers))
+	}
+	if j.supervised.Load() {
+		// Before the queue is replaced. Supervisor catches a racing call.
+		panic(alreadySupervised)
+	}
+
+	q := newPriorityQueue(j.agingEvery, j.agingBy)
+	j.prio = q
+	progressChan, _ = j.Supervisor(maxWorkers, nil)
+
+	go func(doneChan chan struct{}) {
+		// Shutdown, or otherwise done before the queue has drained.
+		<-doneChan
+		q.stop()
+	}(j.doneChan)
+
+	return q.push, progressChan, q.close
+}
@@ -0,0 +1,104 @@
+package racket
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// starve runs a single-worker priority Job where one low priority item competes with a constant inflow of high
+// priority items, each of which submits two more, up to a total of highs. It returns the order the low item
+// was done in, and the total done.
+func starve(highs int, opts ...JobOption) (lowAt, total int) {
+	var (
+		submit    func(PriorityWork)
+		done      func()
+		submitted int // only the one worker touches these, and IsDone is a barrier
+	)
+	lowAt = -1
+
+	j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+		if work.GetString("name") == "low" {
+			lowAt = total
+		}
+		total++
+		time.Sleep(time.Millisecond)
+
+		for range 2 {
+			if submitted == highs {
+				done()
+				return
+			}
+			submitted++
+			submit(PriorityWork{Work: NewWork(map[string]any{"name": "high"}), Priority: 10})
+		}
+	}, opts...)
+
+	submit, pchan, done := j.SupervisorPriority(1)
+	go ProgressLogger(log.New(io.Discard, "", 0), false, nil, pchan, nil)
+
+	// The first high is done while the low is queued, so they compete from then on.
+	submitted++
+	submit(PriorityWork{Work: NewWork(map[string]any{"name": "high"}), Priority: 10})
+	submit(PriorityWork{Work: NewWork(map[string]any{"name": "low"}), Priority: 0})
+
+	<-j.IsDone()
+	close(pchan)
+	return lowAt, total
+}
+
+func Test_PriorityAging(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When low priority Work competes with a constant inflow of high priority Work, it starves", t, func() {
+		lowAt, total := starve(200)
+		So(total, ShouldEqual, 201)
+		So(lowAt, ShouldEqual, 200)
+
+		Convey("... unless it is aged, when it is eventually dispatched, while the inflow continues", func() {
+			lowAt, total := starve(200, WithPriorityAging(time.Millisecond, 1))
+			So(total, ShouldEqual, 201)
+			So(lowAt, ShouldBeBetween, 0, 100)
+		})
+	})
+}
+
+func Test_SupervisorPriority(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When interleaved priorities are submitted to a single worker, they are done highest first, and FIFO within a priority.", t, func() {
+		var order []string
+		gate := make(chan struct{})
+
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			if work.GetBool("gate") {
+				<-gate
+				return
+			}
+			order = append(order, work.GetString("name"))
+		})
+		submit, pchan, done := j.SupervisorPriority(1)
+		go ProgressLogger(log.New(io.Discard, "", 0), false, nil, pchan, nil)
+
+		// Hold the worker, so everything else is queued before any is dispatched.
+		submit(PriorityWork{Work: NewWork(map[string]any{"gate": true})})
+		So(waitFor(time.Second, func() bool { return j.Stats().BusyWorkers == 1 }), ShouldBeTrue)
+
+		for _, pw := range []struct {
+			name     string
+			priority int
+		}{{"c1", 3}, {"a1", 1}, {"e1", 5}, {"a2", 1}, {"c2", 3}, {"e2", 5}, {"b1", 2}} {
+			submit(PriorityWork{Work: NewWork(map[string]any{"name": pw.name}), Priority: pw.priority})
+		}
+		close(gate)
+		done()
+		<-j.IsDone()
+		close(pchan)
+
+		So(order, ShouldResemble, []string{"e1", "e2", "c1", "c2", "b1", "a1", "a2"})
+	})
+}
@@ -1,8 +1,17 @@
 package racket
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 )
 
 // ProgressError is a ProgressType when the Data is an error.
@@ -10,12 +19,42 @@ import (
 // ProgressEsimate is a ProgressType when the Data is a numeric [re]evaluation of how much work is to be performed.
 // ProgressMessage is a ProgressType when the Data is a string message.
 // ProgressOther is a ProgressType when Data is to be consumed elsewhere, and should not be interpretted outside of that elsewhere.
+// ProgressRemaining is a ProgressType when the Data is a numeric count of how much work remains (as opposed to a total estimate).
+// ProgressTimeout is a ProgressType when the Data is an error, specifically because a deadline expired.
+// ProgressSkipped is a ProgressType when the Data is a string reason why Work was skipped (see the Skip* reasons).
+// ProgressEvent is a ProgressType when the Data is a structured Event, such as an audit record.
+// ProgressWarning is a ProgressType when the Data is a string warning: not an error, but more important than a message.
+// ProgressDebug is a ProgressType when the Data is a string for verbose tracing, less important than a message.
+// ProgressPercent is a ProgressType when the Data is a float64 percentage (0-100) of the work that is done.
 const (
 	ProgressError ProgressType = iota
 	ProgressUpdate
 	ProgressEstimate
 	ProgressMessage
 	ProgressOther
+	ProgressRemaining
+	ProgressTimeout
+	ProgressSkipped
+	ProgressEvent
+	ProgressWarning
+	ProgressDebug
+	ProgressPercent
+)
+
+// SkipCancelled is the ProgressSkipped reason when Work wasn't run because the Job was already cancelled.
+// SkipQueueFull is the ProgressSkipped reason when Work was dropped because the queue was full, under OverflowDrop.
+// SkipCacheHit is the ProgressSkipped reason when Work wasn't run because its Result was cached, see WithResultCache.
+const (
+	SkipCancelled = "job cancelled"
+	SkipQueueFull = "queue full"
+	SkipCacheHit  = "cache hit"
+)
+
+// EventAudit is the Event name when the Data is a WorkDiff of how Work was transformed, see WithAudit.
+// EventSummary is the Event name when the Data is a Summary of a Job, see WithSummary.
+const (
+	EventAudit   = "audit"
+	EventSummary = "summary"
 )
 
 type (
@@ -23,10 +62,18 @@ type (
 	ProgressType int
 	// ProgressErrorFunc is a function that consumes an error.
 	ProgressErrorFunc func(error)
-	// Progress is a tuple of a ProgressType and Data. It is also an error and a string.
+	// Progress is a tuple of a ProgressType and Data. It is also an error and a string. It may be stamped (see From)
+	// with the WorkerID that sent it, and the Time it was sent: the zero values mean unset.
 	Progress struct {
-		Type ProgressType
-		Data any
+		Type     ProgressType
+		Data     any
+		WorkerID any
+		Time     time.Time
+	}
+	// Event is the Data of a ProgressEvent: a Name, such as EventAudit, and structured Data specific to it.
+	Event struct {
+		Name string `json:"name"`
+		Data any    `json:"data"`
 	}
 )
 
@@ -43,14 +90,28 @@ func (p ProgressType) String() string {
 		return "ProgressMessage"
 	case ProgressOther:
 		return "ProgressOther"
+	case ProgressRemaining:
+		return "ProgressRemaining"
+	case ProgressTimeout:
+		return "ProgressTimeout"
+	case ProgressSkipped:
+		return "ProgressSkipped"
+	case ProgressEvent:
+		return "ProgressEvent"
+	case ProgressWarning:
+		return "ProgressWarning"
+	case ProgressDebug:
+		return "ProgressDebug"
+	case ProgressPercent:
+		return "ProgressPercent"
 	default:
 		return ""
 	}
 }
 
-// Error returns the Progress Data as an error if Progress is a ProgressError, or nil.
+// Error returns the Progress Data as an error if Progress is a ProgressError or ProgressTimeout, or nil.
 func (p *Progress) Error() error {
-	if p.Type == ProgressError {
+	if p.Type == ProgressError || p.Type == ProgressTimeout {
 		return p.Data.(error)
 	}
 	return nil
@@ -61,41 +122,430 @@ func (p *Progress) String() string {
 	return fmt.Sprintf("%s: %+v", p.Type, p.Data)
 }
 
+// From returns a copy of the Progress stamped with the worker id, and the current Time, e.g.
+// pchan <- PMessagef("fetched %s", url).From(id)
+func (p Progress) From(id any) Progress {
+	p.WorkerID = id
+	p.Time = time.Now()
+	return p
+}
+
+// stamp returns the WorkerID and Time of the Progress as text, e.g. "(worker 3 at 2006-01-02T15:04:05.999Z)", or
+// "" if it's unstamped.
+func (p Progress) stamp() string {
+	var parts []string
+	if p.WorkerID != nil {
+		parts = append(parts, fmt.Sprintf("worker %v", p.WorkerID))
+	}
+	if !p.Time.IsZero() {
+		parts = append(parts, p.Time.Format(time.RFC3339Nano))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "(" + strings.Join(parts, " at ") + ")"
+}
+
+// MarshalJSON returns a JSON object with the name of the ProgressType as "type", and the Data as "data", and if
+// stamped, the WorkerID as "worker_id", and the Time as RFC3339 "time". Errors are marshaled as their message strings.
+func (p Progress) MarshalJSON() ([]byte, error) {
+	data := p.Data
+	if err, ok := data.(error); ok {
+		data = err.Error()
+	}
+	return json.Marshal(struct {
+		Type     string    `json:"type"`
+		Data     any       `json:"data"`
+		WorkerID any       `json:"worker_id,omitempty"`
+		Time     time.Time `json:"time,omitzero"`
+	}{
+		Type:     p.Type.String(),
+		Data:     data,
+		WorkerID: p.WorkerID,
+		Time:     p.Time,
+	})
+}
+
+// LoggerOption is a function that configures a ProgressLogger.
+type LoggerOption func(*loggerConfig)
+
+// loggerConfig is the set of LoggerOptions applied to a ProgressLogger.
+type loggerConfig struct {
+	json   bool
+	ring   *ProgressRing
+	debug  bool
+	stats  *ProgressStats
+	format func(Progress) string
+}
+
+// LogJSON is a LoggerOption that makes ProgressLogger write each logged Progress as a JSON object (see
+// Progress.MarshalJSON), one per line, instead of the "[PROGRESS] ..." text.
+func LogJSON() LoggerOption {
+	return func(c *loggerConfig) {
+		c.json = true
+	}
+}
+
+// LogFormat is a LoggerOption that makes ProgressLogger render each line it logs with the format func, rather than
+// as the "[PROGRESS] ..." text (or JSON, see LogJSON), e.g. to match the app's log style. Which Progress is logged
+// is unchanged. A nil format keeps the default.
+func LogFormat(format func(Progress) string) LoggerOption {
+	return func(c *loggerConfig) {
+		c.format = format
+	}
+}
+
+// LogDebug is a LoggerOption that makes ProgressLogger log ProgressDebug items, which it otherwise drops, even if
+// logMessages is true.
+func LogDebug() LoggerOption {
+	return func(c *loggerConfig) {
+		c.debug = true
+	}
+}
+
+// UnmarshalJSON restores a Progress from the JSON object of MarshalJSON. The Data is restored to the type the
+// ProgressType implies: an error for ProgressError and ProgressTimeout, an int64 for the numeric types (but a
+// float64 for ProgressPercent), a string for ProgressMessage, ProgressWarning, ProgressDebug, and ProgressSkipped,
+// and an Event (with generically-decoded Data) for ProgressEvent. Other types keep the generically-decoded Data.
+// An unknown type name is an error. A "worker_id" is restored generically-decoded (so a numeric ID is a float64),
+// and an RFC3339 "time" as the Time; a "time" in another format is ignored.
+func (p *Progress) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Type     string          `json:"type"`
+		Data     json.RawMessage `json:"data"`
+		WorkerID any             `json:"worker_id"`
+		Time     json.RawMessage `json:"time"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	t, ok := progressTypeNamed(raw.Type)
+	if !ok {
+		return fmt.Errorf("racket: unknown ProgressType %q", raw.Type)
+	}
+
+	var (
+		data any
+		err  error
+	)
+	switch t {
+	case ProgressError, ProgressTimeout:
+		var msg string
+		if err = json.Unmarshal(raw.Data, &msg); err == nil {
+			data = errors.New(msg)
+		}
+	case ProgressUpdate, ProgressEstimate, ProgressRemaining:
+		var n int64
+		err = json.Unmarshal(raw.Data, &n)
+		data = n
+	case ProgressPercent:
+		var pct float64
+		err = json.Unmarshal(raw.Data, &pct)
+		data = pct
+	case ProgressMessage, ProgressWarning, ProgressDebug, ProgressSkipped:
+		var msg string
+		err = json.Unmarshal(raw.Data, &msg)
+		data = msg
+	case ProgressEvent:
+		var e Event
+		err = json.Unmarshal(raw.Data, &e)
+		data = e
+	default:
+		err = json.Unmarshal(raw.Data, &data)
+	}
+	if err != nil {
+		return fmt.Errorf("racket: bad %s data: %w", t, err)
+	}
+
+	p.Type = t
+	p.Data = data
+	p.WorkerID = raw.WorkerID
+	p.Time = time.Time{}
+	if len(raw.Time) > 0 {
+		// Best effort, as the time may have been added by whatever recorded the line.
+		if err := json.Unmarshal(raw.Time, &p.Time); err != nil {
+			p.Time = time.Time{}
+		}
+	}
+	return nil
+}
+
+// progressTypeNamed returns the ProgressType with the String name, and true, or false if there isn't one.
+func progressTypeNamed(name string) (ProgressType, bool) {
+	for t := ProgressError; t <= ProgressPercent; t++ {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// ReplayProgress reads Progress recorded as NDJSON (one MarshalJSON object per line, e.g. from a ProgressLogger
+// with LogJSON and no log prefix or flags), and sends each to out, in order. If the lines have RFC3339 "time"
+// fields, the replay is paced by the recorded gaps between them. Blank lines are skipped. It returns the first
+// error reading or decoding, and does not close out.
+func ReplayProgress(r io.Reader, out chan<- Progress) error {
+	var (
+		scanner = bufio.NewScanner(r)
+		last    time.Time
+	)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var p Progress
+		if err := json.Unmarshal(line, &p); err != nil {
+			return err
+		}
+		if !p.Time.IsZero() {
+			if !last.IsZero() && p.Time.After(last) {
+				time.Sleep(p.Time.Sub(last))
+			}
+			last = p.Time
+		}
+
+		out <- p
+	}
+	return scanner.Err()
+}
+
 // ProgressLogger is a helper that can loop over a Progress channel and triage the items generically.
 // If non-nil, the supplied ProgressErrorFunc will be called with the error after it is logged or printed:
 // Panic'ing or Exit'ing is allowed.
+// Errors, timeouts, and warnings are always logged, debug only with the LogDebug option, and the other types only if
+// logMessages is true.
 // ProgressBar-related Progress will be sent to the barChan as-is.
-func ProgressLogger(outLog *log.Logger, logMessages bool, errf ProgressErrorFunc, progressChan <-chan Progress, barChan chan Progress) {
+func ProgressLogger(outLog *log.Logger, logMessages bool, errf ProgressErrorFunc, progressChan <-chan Progress, barChan chan Progress, opts ...LoggerOption) {
+	var conf loggerConfig
+	for _, opt := range opts {
+		opt(&conf)
+	}
+
+	// logp logs the Progress with the LogFormat if there is one, as JSON if configured to, else as the formatted
+	// text, with its stamp if it has one, and adds the line to the ring.
+	logp := func(p Progress, format string, v ...any) {
+		var line string
+		switch {
+		case conf.format != nil:
+			line = conf.format(p)
+		case conf.json:
+			b, err := json.Marshal(p)
+			if err != nil {
+				line = fmt.Sprintf("[PROGRESS] ERROR: cannot marshal %s Progress: %s", p.Type, err)
+			} else {
+				line = string(b)
+			}
+		default:
+			line = strings.TrimSuffix(fmt.Sprintf(format, v...), "\n")
+			if stamp := p.stamp(); stamp != "" {
+				line = strings.Replace(line, "[PROGRESS] ", "[PROGRESS] "+stamp+" ", 1)
+			}
+		}
+		outLog.Println(line)
+		if conf.ring != nil {
+			conf.ring.add(line)
+		}
+	}
+
 	for p := range progressChan {
 		//outLog.Printf("PROGRESS! %+v\n", p)
+		if conf.stats != nil {
+			conf.stats.add(p)
+		}
+
 		switch p.Type {
 		case ProgressError:
 			// Always print errors.
-			outLog.Printf("[PROGRESS] ERROR: %s\n", p.Data.(error))
+			logp(p, "[PROGRESS] ERROR: %s\n", p.Data.(error))
+
+			if errf != nil {
+				// callback
+				errf(p.Data.(error))
+			}
+		case ProgressTimeout:
+			// Always print timeouts, they're errors too.
+			logp(p, "[PROGRESS] TIMEOUT: %s\n", p.Data.(error))
 
 			if errf != nil {
 				// callback
 				errf(p.Data.(error))
 			}
+		case ProgressWarning:
+			// Always print warnings, they're not errors, but they matter.
+			logp(p, "[PROGRESS] WARNING: %s\n", p.Data.(string))
+		case ProgressDebug:
+			if conf.debug {
+				logp(p, "[PROGRESS] DEBUG: %s\n", p.Data.(string))
+			}
 		case ProgressMessage:
 			if logMessages {
 				// Always print if we're logging.
-				outLog.Printf("[PROGRESS] %s\n", p.Data.(string))
+				logp(p, "[PROGRESS] %s\n", p.Data.(string))
+			}
+		case ProgressEvent:
+			if logMessages {
+				e := p.Data.(Event)
+				logp(p, "[PROGRESS] EVENT %s: %+v\n", e.Name, e.Data)
+			}
+		case ProgressSkipped:
+			if logMessages {
+				logp(p, "[PROGRESS] SKIPPED: %s\n", p.Data.(string))
+			}
+		case ProgressUpdate, ProgressEstimate, ProgressRemaining:
+			if logMessages {
+				logp(p, "[PROGRESS] %s: %d\n", p.Type.String(), p.Data.(int64))
 			}
-		case ProgressUpdate, ProgressEstimate:
+			if barChan != nil {
+				barChan <- p
+			}
+		case ProgressPercent:
 			if logMessages {
-				outLog.Printf("[PROGRESS] %s: %d\n", p.Type.String(), p.Data.(int64))
+				logp(p, "[PROGRESS] %s: %.1f%%\n", p.Type.String(), p.Data.(float64))
 			}
 			if barChan != nil {
 				barChan <- p
 			}
 		default:
 			// Always print weird shit.
-			outLog.Printf("[PROGRESS] ??: %+v\n", p)
+			logp(p, "[PROGRESS] ??: %+v\n", p)
+		}
+	}
+}
+
+// PrefixProgress forwards every Progress from in to out until in is closed, prepending the prefix to the text
+// of ProgressMessage, ProgressWarning, ProgressDebug, ProgressSkipped, ProgressError, and ProgressTimeout items.
+// Other types are forwarded untouched. As out may be shared by several PrefixProgress (e.g. multiple Jobs feeding
+// one ProgressLogger), it is not closed.
+func PrefixProgress(prefix string, in <-chan Progress, out chan<- Progress) {
+	for p := range in {
+		switch p.Type {
+		case ProgressMessage, ProgressWarning, ProgressDebug, ProgressSkipped:
+			p.Data = prefix + p.Data.(string)
+		case ProgressError, ProgressTimeout:
+			p.Data = fmt.Errorf("%s%w", prefix, p.Data.(error))
+		}
+		out <- p
+	}
+}
+
+// ProgressMultiplexer forwards every Progress from in to each of the outs, e.g. to a ProgressLogger, a metrics
+// exporter, and a progress bar, until in is closed, and then closes the outs, once each has received everything.
+// Each out is fed by its own goroutine with an unbounded buffer, so a slow consumer doesn't hold up in, or the other
+// consumers; only its own buffer grows. It returns once the outs are closed, so run it in its own goroutine.
+func ProgressMultiplexer(in <-chan Progress, outs ...chan Progress) {
+	var (
+		wg    sync.WaitGroup
+		feeds = make([]chan Progress, len(outs))
+	)
+	for i, out := range outs {
+		feeds[i] = make(chan Progress)
+		wg.Add(1)
+		go func(feed <-chan Progress) {
+			defer wg.Done()
+			defer close(out)
+			relay(feed, out, 0, OverflowBlock, nil)
+		}(feeds[i])
+	}
+
+	for p := range in {
+		for _, feed := range feeds {
+			feed <- p
+		}
+	}
+	for _, feed := range feeds {
+		close(feed)
+	}
+	wg.Wait()
+}
+
+// ProgressFilter returns a channel of the Progress from in for which keep returns true, dropping the rest, e.g. in
+// front of a ProgressLogger, to suppress the updates. The returned channel is unbuffered, and closed once in is.
+// It panics if keep is nil.
+func ProgressFilter(in <-chan Progress, keep func(Progress) bool) <-chan Progress {
+	if keep == nil {
+		panic("racket: ProgressFilter called with a nil keep func")
+	}
+
+	out := make(chan Progress)
+	go func() {
+		defer close(out)
+		for p := range in {
+			if keep(p) {
+				out <- p
+			}
+		}
+	}()
+	return out
+}
+
+// KeepTypes returns a keep func for ProgressFilter, that keeps only Progress of the types.
+func KeepTypes(types ...ProgressType) func(Progress) bool {
+	return func(p Progress) bool {
+		return slices.Contains(types, p.Type)
+	}
+}
+
+// ChainErrorFuncs returns a ProgressErrorFunc that calls each of the supplied ProgressErrorFuncs, in order,
+// with the same error. Nil entries are skipped.
+func ChainErrorFuncs(fns ...ProgressErrorFunc) ProgressErrorFunc {
+	return func(err error) {
+		for _, f := range fns {
+			if f != nil {
+				f(err)
+			}
 		}
 	}
 }
 
+// GatedErrorFunc returns a ProgressErrorFunc that calls errf only with errors the gate returns true for, e.g.
+// to only have ProgressLogger call back for permanent errors: GatedErrorFunc(IsPermanent, errf).
+func GatedErrorFunc(gate func(error) bool, errf ProgressErrorFunc) ProgressErrorFunc {
+	return func(err error) {
+		if errf != nil && gate(err) {
+			errf(err)
+		}
+	}
+}
+
+// transientError is an error classified as transient.
+type transientError struct {
+	error
+}
+
+// Transient returns true.
+func (t transientError) Transient() bool {
+	return true
+}
+
+// Unwrap returns the underlying error.
+func (t transientError) Unwrap() error {
+	return t.error
+}
+
+// Transient classifies the error as transient (e.g. a blip worth retrying, not worth paging about), such that
+// IsTransient returns true for it, and anything wrapping it. A nil error returns nil.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return transientError{err}
+}
+
+// IsTransient returns true if the error, or any error it wraps, has a Transient() bool method that returns true,
+// such as those from Transient.
+func IsTransient(err error) bool {
+	var t interface{ Transient() bool }
+	return errors.As(err, &t) && t.Transient()
+}
+
+// IsPermanent returns true if the error is not transient, see IsTransient.
+func IsPermanent(err error) bool {
+	return !IsTransient(err)
+}
+
 // PErrorf returns a ProgressError with a formatted error.
 func PErrorf(format string, a ...any) Progress {
 	return Progress{
@@ -104,6 +554,14 @@ func PErrorf(format string, a ...any) Progress {
 	}
 }
 
+// PTimeoutf returns a ProgressTimeout with a formatted error.
+func PTimeoutf(format string, a ...any) Progress {
+	return Progress{
+		Type: ProgressTimeout,
+		Data: fmt.Errorf(format, a...),
+	}
+}
+
 // PMessagef returns a ProgressMessage with a formatted string.
 func PMessagef(format string, a ...any) Progress {
 	return Progress{
@@ -112,6 +570,46 @@ func PMessagef(format string, a ...any) Progress {
 	}
 }
 
+// PWarnf returns a ProgressWarning with a formatted string.
+func PWarnf(format string, a ...any) Progress {
+	return Progress{
+		Type: ProgressWarning,
+		Data: fmt.Sprintf(format, a...),
+	}
+}
+
+// PDebugf returns a ProgressDebug with a formatted string.
+func PDebugf(format string, a ...any) Progress {
+	return Progress{
+		Type: ProgressDebug,
+		Data: fmt.Sprintf(format, a...),
+	}
+}
+
+// PPercent returns a ProgressPercent of the percentage, clamped to 0-100.
+func PPercent(pct float64) Progress {
+	return Progress{
+		Type: ProgressPercent,
+		Data: min(max(pct, 0), 100),
+	}
+}
+
+// PSkipped returns a ProgressSkipped with the reason the Work was skipped.
+func PSkipped(reason string) Progress {
+	return Progress{
+		Type: ProgressSkipped,
+		Data: reason,
+	}
+}
+
+// PEvent returns a ProgressEvent with an Event of the name and data.
+func PEvent(name string, data any) Progress {
+	return Progress{
+		Type: ProgressEvent,
+		Data: Event{Name: name, Data: data},
+	}
+}
+
 // PUpdate returns a ProgressUpdate with the specified count.
 func PUpdate(count int64) Progress {
 	return Progress{
@@ -120,6 +618,11 @@ func PUpdate(count int64) Progress {
 	}
 }
 
+// PBytes returns a ProgressUpdate with the specified count of bytes, for byte-oriented Work such as copies.
+func PBytes(count int64) Progress {
+	return PUpdate(count)
+}
+
 // PEstimate returns a ProgressEstimate with the specified estimate.
 func PEstimate(estimate int64) Progress {
 	return Progress{
@@ -127,3 +630,11 @@ func PEstimate(estimate int64) Progress {
 		Data: estimate,
 	}
 }
+
+// PRemaining returns a ProgressRemaining with the specified count of remaining work.
+func PRemaining(remaining int64) Progress {
+	return Progress{
+		Type: ProgressRemaining,
+		Data: remaining,
+	}
+}
@@ -1,10 +1,17 @@
 package racket
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/fortytw2/leaktest"
 	. "github.com/smartystreets/goconvey/convey"
@@ -29,7 +36,9 @@ func Test_ProgressLogger(t *testing.T) {
 
 		// The easy
 		pchan <- PMessagef("Hello")
+		pchan <- PWarnf("Warning!")
 		pchan <- PErrorf("Error!")
+		pchan <- PTimeoutf("Timeout!")
 
 		// Make sure the bar is notified
 		pchan <- PEstimate(42)
@@ -39,6 +48,14 @@ func Test_ProgressLogger(t *testing.T) {
 		pchan <- PUpdate(-1)
 		So(<-bchan, ShouldEqual, PUpdate(-1))
 
+		// Make sure the bar is notified
+		pchan <- PRemaining(41)
+		So(<-bchan, ShouldEqual, PRemaining(41))
+
+		// Make sure the bar is notified
+		pchan <- PPercent(42)
+		So(<-bchan, ShouldEqual, PPercent(42))
+
 		// Make sure weird stuff doesn't blow up
 		pchan <- Progress{
 			Type: ProgressCrap,
@@ -46,9 +63,393 @@ func Test_ProgressLogger(t *testing.T) {
 		}
 
 		// Make sure errorCount was eventually incremented
-		So(errorCount, ShouldEqual, 1)
+		So(errorCount, ShouldEqual, 2)
+	})
+
+}
+
+func Test_ProgressLoggerWarning(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a ProgressLogger isn't logging messages, it still logs warnings, but not messages.", t, func() {
+		var buf bytes.Buffer
+		pchan := make(chan Progress, 2)
+		pchan <- PMessagef("Hello")
+		pchan <- PWarnf("disk %d%% full", 90)
+		close(pchan)
+
+		ProgressLogger(log.New(&buf, "", 0), false, nil, pchan, nil)
+		So(buf.String(), ShouldEqual, "[PROGRESS] WARNING: disk 90% full\n")
+	})
+}
+
+func Test_ProgressLoggerDebug(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	// logged returns what a ProgressLogger logging messages logs of a message and a debug.
+	logged := func(opts ...LoggerOption) string {
+		var buf bytes.Buffer
+		pchan := make(chan Progress, 2)
+		pchan <- PMessagef("Hello")
+		pchan <- PDebugf("item %d took %s", 7, "3ms")
+		close(pchan)
+
+		ProgressLogger(log.New(&buf, "", 0), true, nil, pchan, nil, opts...)
+		return buf.String()
+	}
+
+	Convey("When a ProgressLogger is logging messages, debug is still dropped by default.", t, func() {
+		So(logged(), ShouldEqual, "[PROGRESS] Hello\n")
 	})
 
+	Convey("When a ProgressLogger has LogDebug, debug is logged.", t, func() {
+		So(logged(LogDebug()), ShouldEqual, "[PROGRESS] Hello\n[PROGRESS] DEBUG: item 7 took 3ms\n")
+	})
+}
+
+func Test_ProgressLoggerFormat(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	// logged returns what a ProgressLogger logging messages logs of a message, an update, and an error.
+	logged := func(opts ...LoggerOption) string {
+		var buf bytes.Buffer
+		pchan := make(chan Progress, 3)
+		pchan <- PMessagef("Hello")
+		pchan <- PUpdate(1)
+		pchan <- PErrorf("Error!")
+		close(pchan)
+
+		ProgressLogger(log.New(&buf, "", 0), true, nil, pchan, nil, opts...)
+		return buf.String()
+	}
+
+	Convey("When a ProgressLogger has a LogFormat, each line it logs is the format's.", t, func() {
+		format := func(p Progress) string {
+			if err := p.Error(); err != nil {
+				return "req-42 ❌ " + err.Error()
+			}
+			return fmt.Sprintf("req-42 %s %v", p.Type, p.Data)
+		}
+		So(logged(LogFormat(format), LogJSON()), ShouldEqual,
+			"req-42 ProgressMessage Hello\nreq-42 ProgressUpdate 1\nreq-42 ❌ Error!\n")
+	})
+
+	Convey("When a ProgressLogger has a nil LogFormat, it logs as usual.", t, func() {
+		So(logged(LogFormat(nil)), ShouldEqual, logged())
+		So(logged(), ShouldEqual, "[PROGRESS] Hello\n[PROGRESS] ProgressUpdate: 1\n[PROGRESS] ERROR: Error!\n")
+	})
+}
+
+func Test_ProgressLoggerJSON(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a ProgressLogger logs JSON, each line is a JSON object with type and data fields.", t, func() {
+		var buf bytes.Buffer
+		pchan := make(chan Progress, 4)
+		pchan <- PMessagef("Hello")
+		pchan <- PErrorf("Error!")
+		pchan <- PUpdate(42)
+		pchan <- PTimeoutf("Timeout!")
+		close(pchan)
+
+		ProgressLogger(log.New(&buf, "", 0), true, nil, pchan, nil, LogJSON())
+
+		var lines []map[string]any
+		scanner := bufio.NewScanner(&buf)
+		for scanner.Scan() {
+			var line map[string]any
+			So(json.Unmarshal(scanner.Bytes(), &line), ShouldBeNil)
+			So(line, ShouldContainKey, "type")
+			So(line, ShouldContainKey, "data")
+			lines = append(lines, line)
+		}
+		So(lines, ShouldHaveLength, 4)
+		So(lines[0]["type"], ShouldEqual, "ProgressMessage")
+		So(lines[0]["data"], ShouldEqual, "Hello")
+		So(lines[1]["type"], ShouldEqual, "ProgressError")
+		So(lines[1]["data"], ShouldEqual, "Error!")
+		So(lines[2]["type"], ShouldEqual, "ProgressUpdate")
+		So(lines[2]["data"], ShouldEqual, 42)
+		So(lines[3]["type"], ShouldEqual, "ProgressTimeout")
+	})
+}
+
+func Test_ProgressFrom(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	when := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	Convey("When a Progress is stamped From a worker, it has the worker ID and the time, and is otherwise unchanged.", t, func() {
+		before := time.Now()
+		p := PMessagef("Hello").From(3)
+		So(p.Type, ShouldEqual, ProgressMessage)
+		So(p.Data, ShouldEqual, "Hello")
+		So(p.WorkerID, ShouldEqual, 3)
+		So(p.Time, ShouldHappenOnOrBetween, before, time.Now())
+		So(PMessagef("Hello"), ShouldEqual, Progress{Type: ProgressMessage, Data: "Hello"})
+	})
+
+	Convey("When a ProgressLogger logs stamped Progress, the text has the worker ID and the time, where set.", t, func() {
+		var buf bytes.Buffer
+		pchan := make(chan Progress, 4)
+		pchan <- Progress{Type: ProgressMessage, Data: "Hello", WorkerID: 3, Time: when}
+		pchan <- Progress{Type: ProgressError, Data: errors.New("Error!"), WorkerID: "w1"}
+		pchan <- Progress{Type: ProgressUpdate, Data: int64(1), Time: when}
+		pchan <- PMessagef("Unstamped")
+		close(pchan)
+
+		ProgressLogger(log.New(&buf, "", 0), true, nil, pchan, nil)
+		So(buf.String(), ShouldEqual, "[PROGRESS] (worker 3 at 2026-01-01T12:00:00Z) Hello\n"+
+			"[PROGRESS] (worker w1) ERROR: Error!\n"+
+			"[PROGRESS] (2026-01-01T12:00:00Z) ProgressUpdate: 1\n"+
+			"[PROGRESS] Unstamped\n")
+	})
+
+	Convey("When stamped Progress is marshaled, the worker ID and time round-trip, and are omitted if unset.", t, func() {
+		b, err := json.Marshal(Progress{Type: ProgressMessage, Data: "Hello", WorkerID: 3, Time: when})
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{"type":"ProgressMessage","data":"Hello","worker_id":3,"time":"2026-01-01T12:00:00Z"}`)
+
+		var p Progress
+		So(json.Unmarshal(b, &p), ShouldBeNil)
+		So(p.WorkerID, ShouldEqual, 3.0)
+		So(p.Time.Equal(when), ShouldBeTrue)
+
+		b, err = json.Marshal(PMessagef("Hello"))
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{"type":"ProgressMessage","data":"Hello"}`)
+
+		So(json.Unmarshal([]byte(`{"type":"ProgressMessage","data":"Hello","time":"yesterday"}`), &p), ShouldBeNil)
+		So(p, ShouldEqual, PMessagef("Hello"))
+	})
+}
+
+func Test_ReplayProgress(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When recorded NDJSON Progress is replayed, the reconstructed Progress matches, in order.", t, func() {
+		recorded := []Progress{
+			PMessagef("Hello"),
+			PWarnf("Warning!"),
+			PDebugf("Debug!"),
+			PPercent(12.5),
+			PEstimate(100),
+			PUpdate(42),
+			PRemaining(58),
+			PErrorf("Error!"),
+			PTimeoutf("Timeout!"),
+			PSkipped(SkipCacheHit),
+			PEvent(EventAudit, map[string]any{"n": 1.0}),
+		}
+
+		var buf bytes.Buffer
+		pchan := make(chan Progress, len(recorded))
+		for _, p := range recorded {
+			pchan <- p
+		}
+		close(pchan)
+		ProgressLogger(log.New(&buf, "", 0), true, nil, pchan, nil, LogJSON(), LogDebug())
+
+		out := make(chan Progress, len(recorded))
+		So(ReplayProgress(&buf, out), ShouldBeNil)
+		close(out)
+
+		var replayed []Progress
+		for p := range out {
+			replayed = append(replayed, p)
+		}
+		So(replayed, ShouldHaveLength, len(recorded))
+		for i, p := range replayed {
+			So(p.Type, ShouldEqual, recorded[i].Type)
+			if err := recorded[i].Error(); err != nil {
+				So(p.Error(), ShouldBeError, err.Error())
+			} else {
+				So(p.Data, ShouldResemble, recorded[i].Data)
+			}
+		}
+	})
+
+	Convey("When recorded NDJSON has timestamps, the replay is paced by them.", t, func() {
+		ndjson := `{"type":"ProgressUpdate","data":1,"time":"2026-01-01T00:00:00Z"}
+
+{"type":"ProgressUpdate","data":2,"time":"2026-01-01T00:00:00.05Z"}
+`
+		out := make(chan Progress, 2)
+		start := time.Now()
+		So(ReplayProgress(strings.NewReader(ndjson), out), ShouldBeNil)
+		So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 50*time.Millisecond)
+		p := <-out
+		So(p.Data, ShouldEqual, 1)
+		So(p.Time.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)), ShouldBeTrue)
+		p = <-out
+		So(p.Data, ShouldEqual, 2)
+		So(p.Time.Equal(time.Date(2026, 1, 1, 0, 0, 0, 50*int(time.Millisecond), time.UTC)), ShouldBeTrue)
+	})
+
+	Convey("When recorded NDJSON is bad, ReplayProgress returns an error.", t, func() {
+		out := make(chan Progress, 2)
+		So(ReplayProgress(strings.NewReader(`{"type":"ProgressCrap","data":1}`), out), ShouldBeError)
+		So(ReplayProgress(strings.NewReader(`{"type":"ProgressUpdate","data":"one"}`), out), ShouldBeError)
+		So(ReplayProgress(strings.NewReader(`not json`), out), ShouldBeError)
+	})
+}
+
+func Test_PrefixProgress(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When Progress is prefixed, only messages and errors get the prefix", t, func() {
+		in := make(chan Progress)
+		out := make(chan Progress)
+		defer close(out)
+		go PrefixProgress("[job1] ", in, out)
+		defer close(in)
+
+		in <- PMessagef("Hello")
+		So(<-out, ShouldEqual, PMessagef("[job1] Hello"))
+
+		e := fmt.Errorf("an ERROR")
+		in <- Progress{Type: ProgressError, Data: e}
+		pe := <-out
+		So(pe.Error().Error(), ShouldEqual, "[job1] an ERROR")
+		So(errors.Is(pe.Error(), e), ShouldBeTrue)
+
+		in <- PWarnf("Careful")
+		So(<-out, ShouldEqual, PWarnf("[job1] Careful"))
+
+		in <- PUpdate(1)
+		So(<-out, ShouldEqual, PUpdate(1))
+
+		in <- PEstimate(42)
+		So(<-out, ShouldEqual, PEstimate(42))
+	})
+}
+
+func Test_ProgressMultiplexer(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	its := 100
+
+	Convey("When Progress is multiplexed to three consumers, each receives all of it, in order, even if one is slow.", t, func() {
+		in := make(chan Progress)
+		outs := []chan Progress{make(chan Progress), make(chan Progress), make(chan Progress)}
+		muxed := make(chan struct{})
+		go func() {
+			defer close(muxed)
+			ProgressMultiplexer(in, outs...)
+		}()
+
+		received := make([][]int64, len(outs))
+		var wg sync.WaitGroup
+		for i, out := range outs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for p := range out {
+					if i == 0 {
+						time.Sleep(time.Millisecond) // the slow one
+					}
+					received[i] = append(received[i], p.Data.(int64))
+				}
+			}()
+		}
+
+		start := time.Now()
+		for i := range its {
+			in <- PUpdate(int64(i))
+		}
+		// The slow consumer takes at least its ms, but doesn't hold up the sender.
+		So(time.Since(start), ShouldBeLessThan, time.Duration(its)*time.Millisecond)
+		close(in)
+		<-muxed
+		wg.Wait()
+
+		expected := make([]int64, its)
+		for i := range expected {
+			expected[i] = int64(i)
+		}
+		for i := range outs {
+			So(received[i], ShouldResemble, expected)
+		}
+	})
+}
+
+func Test_ProgressFilter(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When Progress is filtered to errors, the updates and messages are dropped, and the channel still closes.", t, func() {
+		in := make(chan Progress)
+		out := ProgressFilter(in, KeepTypes(ProgressError))
+
+		go func() {
+			defer close(in)
+			in <- PUpdate(1)
+			in <- PErrorf("first")
+			in <- PMessagef("Hello")
+			in <- PEstimate(42)
+			in <- PErrorf("second")
+			in <- PTimeoutf("not an error type")
+		}()
+
+		var errs []string
+		for p := range out {
+			errs = append(errs, p.Error().Error())
+		}
+		So(errs, ShouldResemble, []string{"first", "second"})
+	})
+
+	Convey("When Progress is filtered with a nil keep func, it panics.", t, func() {
+		So(func() { ProgressFilter(make(chan Progress), nil) }, ShouldPanicWith, "racket: ProgressFilter called with a nil keep func")
+	})
+}
+
+func Test_ChainErrorFuncs(t *testing.T) {
+	Convey("When ProgressErrorFuncs are chained, each receives the error in order", t, func() {
+		var calls []string
+		record := func(name string) ProgressErrorFunc {
+			return func(e error) {
+				calls = append(calls, fmt.Sprintf("%s: %s", name, e))
+			}
+		}
+
+		errf := ChainErrorFuncs(record("count"), nil, record("log"), record("alert"))
+		errf(fmt.Errorf("boom"))
+
+		So(calls, ShouldResemble, []string{"count: boom", "log: boom", "alert: boom"})
+	})
+
+	Convey("When no ProgressErrorFuncs are chained, nothing blows up", t, func() {
+		So(func() { ChainErrorFuncs()(fmt.Errorf("boom")) }, ShouldNotPanic)
+	})
+}
+
+func Test_GatedErrorFunc(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a ProgressLogger's errf is gated to permanent errors, it only fires for them.", t, func() {
+		var errs []error
+		errf := GatedErrorFunc(IsPermanent, func(e error) {
+			errs = append(errs, e)
+		})
+
+		pchan := make(chan Progress, 2)
+		pchan <- PErrorf("connection reset: %w", Transient(errors.New("blip")))
+		pchan <- PErrorf("bad input")
+		close(pchan)
+		ProgressLogger(log.New(io.Discard, "", 0), false, errf, pchan, nil)
+
+		So(errs, ShouldHaveLength, 1)
+		So(errs[0], ShouldBeError, "bad input")
+	})
+
+	Convey("Errors are classified as transient through wrapping, and nil stays nil.", t, func() {
+		err := Transient(errors.New("blip"))
+		So(IsTransient(err), ShouldBeTrue)
+		So(IsTransient(fmt.Errorf("wrapped: %w", err)), ShouldBeTrue)
+		So(IsPermanent(err), ShouldBeFalse)
+		So(IsTransient(errors.New("bad")), ShouldBeFalse)
+		So(errors.Unwrap(err), ShouldBeError, "blip")
+		So(Transient(nil), ShouldBeNil)
+	})
 }
 
 func Test_ProgressType(t *testing.T) {
@@ -76,6 +477,72 @@ func Test_ProgressType(t *testing.T) {
 		So(pe.String(), ShouldEqual, "ProgressError: an ERROR")
 	})
 
+	Convey("ProgressTimeout and shortcuts, behave and resolve properly", t, func() {
+		pe := PTimeoutf("a TIMEOUT")
+		So(pe, ShouldHaveSameTypeAs, Progress{})
+		So(pe.Type, ShouldEqual, ProgressTimeout)
+		So(pe.Type.String(), ShouldEqual, "ProgressTimeout")
+		So(pe.Data, ShouldBeError)
+		So(pe.Error(), ShouldEqual, fmt.Errorf("a TIMEOUT"))
+		So(pe.String(), ShouldEqual, "ProgressTimeout: a TIMEOUT")
+	})
+
+	Convey("ProgressEvent and shortcuts, behave and resolve properly", t, func() {
+		pe := PEvent(EventAudit, map[string]int{"n": 1})
+		So(pe, ShouldHaveSameTypeAs, Progress{})
+		So(pe.Type, ShouldEqual, ProgressEvent)
+		So(pe.Type.String(), ShouldEqual, "ProgressEvent")
+		So(pe.Data, ShouldResemble, Event{Name: "audit", Data: map[string]int{"n": 1}})
+		So(pe.Error(), ShouldBeNil)
+
+		b, err := json.Marshal(pe)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{"type":"ProgressEvent","data":{"name":"audit","data":{"n":1}}}`)
+	})
+
+	Convey("ProgressSkipped and shortcuts, behave and resolve properly", t, func() {
+		pe := PSkipped(SkipQueueFull)
+		So(pe, ShouldHaveSameTypeAs, Progress{})
+		So(pe.Type, ShouldEqual, ProgressSkipped)
+		So(pe.Type.String(), ShouldEqual, "ProgressSkipped")
+		So(pe.Data, ShouldEqual, "queue full")
+		So(pe.Error(), ShouldBeNil)
+		So(pe.String(), ShouldEqual, "ProgressSkipped: queue full")
+	})
+
+	Convey("ProgressWarning and shortcuts, behave and resolve properly", t, func() {
+		pe := PWarnf("WARNING!")
+		So(pe, ShouldHaveSameTypeAs, Progress{})
+		So(pe.Type, ShouldEqual, ProgressWarning)
+		So(pe.Type.String(), ShouldEqual, "ProgressWarning")
+		So(pe.Data, ShouldHaveSameTypeAs, "Hello World")
+		So(pe.Error(), ShouldBeNil)
+		So(pe.String(), ShouldEqual, "ProgressWarning: WARNING!")
+	})
+
+	Convey("ProgressDebug and shortcuts, behave and resolve properly", t, func() {
+		pe := PDebugf("DEBUG!")
+		So(pe, ShouldHaveSameTypeAs, Progress{})
+		So(pe.Type, ShouldEqual, ProgressDebug)
+		So(pe.Type.String(), ShouldEqual, "ProgressDebug")
+		So(pe.Data, ShouldHaveSameTypeAs, "Hello World")
+		So(pe.Error(), ShouldBeNil)
+		So(pe.String(), ShouldEqual, "ProgressDebug: DEBUG!")
+	})
+
+	Convey("ProgressPercent and shortcuts, behave and resolve properly", t, func() {
+		pe := PPercent(42.5)
+		So(pe, ShouldHaveSameTypeAs, Progress{})
+		So(pe.Type, ShouldEqual, ProgressPercent)
+		So(pe.Type.String(), ShouldEqual, "ProgressPercent")
+		So(pe.Data, ShouldEqual, 42.5)
+		So(pe.Error(), ShouldBeNil)
+		So(pe.String(), ShouldEqual, "ProgressPercent: 42.5")
+
+		So(PPercent(-1).Data, ShouldEqual, 0)
+		So(PPercent(101).Data, ShouldEqual, 100)
+	})
+
 	Convey("ProgressMessage and shortcuts, behave and resolve properly", t, func() {
 		pe := PMessagef("MESSAGE!")
 		So(pe, ShouldHaveSameTypeAs, Progress{})
@@ -106,6 +573,16 @@ func Test_ProgressType(t *testing.T) {
 		So(pe.String(), ShouldEqual, "ProgressEstimate: 4026")
 	})
 
+	Convey("ProgressRemaining and shortcuts, behave and resolve properly", t, func() {
+		pe := PRemaining(42)
+		So(pe, ShouldHaveSameTypeAs, Progress{})
+		So(pe.Type, ShouldEqual, ProgressRemaining)
+		So(pe.Type.String(), ShouldEqual, "ProgressRemaining")
+		So(pe.Data, ShouldHaveSameTypeAs, int64(1024))
+		So(pe.Error(), ShouldBeNil)
+		So(pe.String(), ShouldEqual, "ProgressRemaining: 42")
+	})
+
 	Convey("ProgressOther behaves and resolve properly", t, func() {
 		pe := Progress{
 			Type: ProgressOther,
@@ -0,0 +1,182 @@
+package racket
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowBlock is an OverflowPolicy where Add blocks until there is room in the queue.
+// OverflowDrop is an OverflowPolicy where Add rejects Work if there is no room in the queue.
+// OverflowDropOldest is an OverflowPolicy where Add discards the oldest queued Work to make room.
+const (
+	OverflowBlock OverflowPolicy = iota
+	OverflowDrop
+	OverflowDropOldest
+)
+
+// OverflowPolicy is one of the constant policies for what a QueuedJob does with Work when its queue is full.
+type OverflowPolicy int
+
+// QueueOption is a functional option to tune the behavior of a QueuedJob.
+type QueueOption func(*QueuedJob)
+
+// WithEnqueueTransforms registers a chain of transforms (e.g. normalize, enrich, validate) that are applied,
+// in order, to every Work at Add time, so it is clean by the time it is dispatched. If a transform returns an
+// error, the Work is rejected: Add returns false, and the error is sent as a ProgressError.
+func WithEnqueueTransforms(fns ...func(Work) (Work, error)) QueueOption {
+	return func(q *QueuedJob) {
+		q.transforms = append(q.transforms, fns...)
+	}
+}
+
+// WithAudit sends a ProgressEvent named EventAudit, with the WorkDiff, for each Work that the enqueue transforms
+// changed, recording how it was transformed between Add and dispatch.
+func WithAudit() QueueOption {
+	return func(q *QueuedJob) {
+		q.audit = true
+	}
+}
+
+// QueuedJob wraps a Job with a buffered queue of Work, and hides the work channel and done dance behind
+// Add and Done.
+type QueuedJob struct {
+	job          Job
+	queue        chan Work
+	policy       OverflowPolicy
+	progressChan chan Progress
+	lock         sync.RWMutex // guards closed, and starting an Add
+	closed       bool
+	adding       sync.WaitGroup // Adds in progress, which the queue isn't closed under
+	stopped      atomic.Bool    // the Job is done, so queued Work is discarded
+	transforms   []func(Work) (Work, error)
+	audit        bool
+}
+
+// NewQueuedJob hires a Supervisor for the Job to oversee maxWorkers, fed from a queue that holds up to size
+// items of Work, and returns a QueuedJob. When the queue is full, Add follows the OverflowPolicy.
+func NewQueuedJob(job Job, maxWorkers, size int, policy OverflowPolicy, opts ...QueueOption) *QueuedJob {
+	q := &QueuedJob{
+		job:    job,
+		queue:  make(chan Work, size),
+		policy: policy,
+	}
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	wchan := make(chan Work)
+	q.progressChan, _ = job.Supervisor(maxWorkers, wchan)
+	isDone := job.IsDone()
+
+	go func() {
+		for w := range q.queue {
+			select {
+			case wchan <- w:
+			case <-isDone:
+				// The Job stopped early (e.g. StopAfterSuccesses), so nothing will receive the Work. Discard the
+				// rest, so Add never blocks, until the queue is closed.
+				q.stopped.Store(true)
+				for range q.queue {
+				}
+				return
+			}
+		}
+		// The queue is closed and drained, but let anything in-flight finish retrying.
+		job.DoneAllowRetries()
+	}()
+
+	return q
+}
+
+// Add puts the Work on the queue, returning true if it was accepted. If the queue is full, Add blocks or
+// rejects the Work (or the oldest queued Work) per the OverflowPolicy, sending a ProgressSkipped for any dropped.
+// Work added after Done, or rejected by an enqueue transform, is also rejected. Work added before Done is still
+// queued, even if Add is blocked when Done is called. If the Job stops early (e.g. StopAfterSuccesses), queued
+// Work is discarded, and Work added after is rejected.
+func (q *QueuedJob) Add(work Work) bool {
+	q.lock.RLock()
+	if q.closed || q.stopped.Load() {
+		q.lock.RUnlock()
+		return false
+	}
+	q.adding.Add(1)
+	q.lock.RUnlock() // so a blocked Add doesn't stall Done
+	defer q.adding.Done()
+
+	original := work
+	for _, transform := range q.transforms {
+		var err error
+		if work, err = transform(work); err != nil {
+			q.progressChan <- PErrorf("work rejected: %w", err)
+			return false
+		}
+	}
+	if q.audit {
+		if diff := original.Diff(work); !diff.Empty() {
+			q.progressChan <- PEvent(EventAudit, diff)
+		}
+	}
+
+	switch q.policy {
+	case OverflowDrop:
+		select {
+		case q.queue <- work:
+			return true
+		default:
+			q.progressChan <- PSkipped(SkipQueueFull)
+			return false
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case q.queue <- work:
+				return true
+			default:
+			}
+			select {
+			case <-q.queue:
+				q.progressChan <- PSkipped(SkipQueueFull)
+			default:
+			}
+		}
+	}
+	q.queue <- work
+	return true
+}
+
+// AddAll Adds each of the items, in order, and returns the number accepted.
+func (q *QueuedJob) AddAll(items []Work) int {
+	var accepted int
+	for _, w := range items {
+		if q.Add(w) {
+			accepted++
+		}
+	}
+	return accepted
+}
+
+// Progress returns the channel to receive Progress on. The caller is responsible for closing it
+// after IsDone fires, as with Supervisor.
+func (q *QueuedJob) Progress() chan Progress {
+	return q.progressChan
+}
+
+// Done signals that no more Work will be added. Work already queued, or being Added, will still be dispatched.
+// It doesn't wait for a blocked Add. It is safe to call more than once.
+func (q *QueuedJob) Done() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if !q.closed {
+		q.closed = true
+		go func() {
+			q.adding.Wait()
+			close(q.queue)
+		}()
+	}
+}
+
+// IsDone will wait until Done has been called, and all of the queued Work has been completed.
+func (q *QueuedJob) IsDone() <-chan bool {
+	return q.job.IsDone()
+}
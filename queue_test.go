@@ -0,0 +1,290 @@
+package racket
+
+import (
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_QueuedJob(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	Convey("When a QueuedJob with a Block policy has more Work added than its buffer, all of it is accepted.", t, func(c C) {
+		var wCount atomic.Int64
+		its := 20
+
+		q := NewQueuedJob(NewJob(func(id any, work Work, pchan chan<- Progress) {
+			wCount.Add(1)
+		}), 2, 5, OverflowBlock)
+		defer close(q.Progress())
+		go ProgressLogger(disco, false, nil, q.Progress(), nil)
+
+		items := make([]Work, its)
+		for i := range items {
+			items[i] = NewWork(map[string]any{"n": i})
+		}
+
+		c.So(q.AddAll(items), ShouldEqual, its)
+		q.Done()
+		q.Done() // safe to call twice
+		c.So(q.Add(NewWork(nil)), ShouldBeFalse)
+
+		<-q.IsDone()
+		c.So(wCount.Load(), ShouldEqual, its)
+	})
+
+	Convey("When a QueuedJob with a Drop policy is full, Work is rejected.", t, func(c C) {
+		var wCount atomic.Int64
+		gate := make(chan struct{})
+
+		q := NewQueuedJob(NewJob(func(id any, work Work, pchan chan<- Progress) {
+			<-gate
+			wCount.Add(1)
+		}), 1, 2, OverflowDrop)
+		defer close(q.Progress())
+		go ProgressLogger(disco, false, nil, q.Progress(), nil)
+
+		// One in the worker, one in flight to the worker, and two queued, at most.
+		accepted := q.AddAll(make([]Work, 10))
+		c.So(accepted, ShouldBeBetweenOrEqual, 2, 4)
+
+		close(gate)
+		q.Done()
+		<-q.IsDone()
+		c.So(wCount.Load(), ShouldEqual, accepted)
+	})
+
+	Convey("When a QueuedJob's Job stops early, the rest of the Work is discarded, and Add doesn't block.", t, func(c C) {
+		var wCount atomic.Int64
+
+		q := NewQueuedJob(NewJob(func(id any, work Work, pchan chan<- Progress) {
+			wCount.Add(1)
+		}, StopAfterSuccesses(1)), 1, 2, OverflowBlock)
+		defer close(q.Progress())
+		go ProgressLogger(disco, false, nil, q.Progress(), nil)
+
+		for range 5 {
+			q.Add(NewWork(nil))
+		}
+		<-q.IsDone()
+		c.So(waitFor(time.Second, func() bool { return !q.Add(NewWork(nil)) }), ShouldBeTrue)
+		q.Done()
+		c.So(wCount.Load(), ShouldEqual, 1)
+	})
+
+	Convey("When a QueuedJob with a Block policy has an Add blocked, Done doesn't wait for it, and the Work is still done.", t, func(c C) {
+		var wCount atomic.Int64
+		gate := make(chan struct{})
+		adding := make(chan struct{}) // the last Add is under way
+
+		q := NewQueuedJob(NewJob(func(id any, work Work, pchan chan<- Progress) {
+			<-gate
+			wCount.Add(1)
+		}), 1, 1, OverflowBlock, WithEnqueueTransforms(func(w Work) (Work, error) {
+			if w.GetBool("last") {
+				close(adding)
+			}
+			return w, nil
+		}))
+		defer close(q.Progress())
+		go ProgressLogger(disco, false, nil, q.Progress(), nil)
+
+		// One in the worker, one in flight to the worker, and one queued, then the next blocks.
+		for range 3 {
+			c.So(q.Add(NewWork(nil)), ShouldBeTrue)
+		}
+		added := make(chan bool)
+		go func() {
+			added <- q.Add(NewWork(map[string]any{"last": true}))
+		}()
+		<-adding
+
+		doneReturned := make(chan struct{})
+		go func() {
+			defer close(doneReturned)
+			q.Done()
+		}()
+		select {
+		case <-doneReturned:
+		case <-time.After(time.Second):
+			c.So("Done is stalled by the blocked Add", ShouldBeEmpty)
+		}
+		c.So(q.Add(NewWork(nil)), ShouldBeFalse)
+
+		close(gate)
+		c.So(<-added, ShouldBeTrue)
+		<-q.IsDone()
+		c.So(wCount.Load(), ShouldEqual, 4)
+	})
+}
+
+func Test_QueuedJobEnqueueTransforms(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	normalize := func(w Work) (Work, error) {
+		return NewWork(map[string]any{"name": strings.ToLower(w.GetString("name"))}), nil
+	}
+	validate := func(w Work) (Work, error) {
+		if w.GetString("name") == "" {
+			return w, errors.New("name is required")
+		}
+		return w, nil
+	}
+
+	Convey("When a QueuedJob has enqueue transforms, passing Work is transformed and failing Work is rejected.", t, func(c C) {
+		var (
+			lock   sync.Mutex
+			names  []string
+			eCount atomic.Int64
+		)
+
+		q := NewQueuedJob(NewJob(func(id any, work Work, pchan chan<- Progress) {
+			lock.Lock()
+			defer lock.Unlock()
+			names = append(names, work.GetString("name"))
+		}), 1, 5, OverflowBlock, WithEnqueueTransforms(normalize, validate))
+		defer close(q.Progress())
+		go ProgressLogger(disco, false, func(e error) {
+			c.So(e.Error(), ShouldEqual, "work rejected: name is required")
+			eCount.Add(1)
+		}, q.Progress(), nil)
+
+		c.So(q.Add(NewWork(map[string]any{"name": "HELLO"})), ShouldBeTrue)
+		c.So(q.Add(NewWork(nil)), ShouldBeFalse)
+		q.Done()
+		<-q.IsDone()
+
+		lock.Lock()
+		defer lock.Unlock()
+		c.So(names, ShouldResemble, []string{"hello"})
+		// IsDone doesn't wait for the consumer, so the last errf may still be running.
+		c.So(waitFor(time.Second, func() bool { return eCount.Load() == 1 }), ShouldBeTrue)
+	})
+}
+
+func Test_QueuedJobSkippedProgress(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a QueuedJob drops Work, a ProgressSkipped is sent with the reason.", t, func(c C) {
+		gate := make(chan struct{})
+
+		q := NewQueuedJob(NewJob(func(id any, work Work, pchan chan<- Progress) {
+			<-gate
+		}), 1, 2, OverflowDrop)
+		defer close(q.Progress())
+
+		var skipped atomic.Int64
+		go func() {
+			for p := range q.Progress() {
+				if p.Type == ProgressSkipped && p.Data == SkipQueueFull {
+					skipped.Add(1)
+				}
+			}
+		}()
+
+		its := 10
+		accepted := q.AddAll(make([]Work, its))
+
+		close(gate)
+		q.Done()
+		<-q.IsDone()
+		// IsDone doesn't wait for the consumer, so the last skip may still be in flight.
+		c.So(waitFor(time.Second, func() bool { return skipped.Load() == int64(its-accepted) }), ShouldBeTrue)
+	})
+}
+
+func Test_QueuedJobAudit(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	enrich := func(w Work) (Work, error) {
+		return w.Merge(NewWork(map[string]any{"region": "us-east"})), nil
+	}
+
+	Convey("When a QueuedJob audits enqueue transforms, an audit event records how the Work changed.", t, func(c C) {
+		q := NewQueuedJob(NewJob(func(id any, work Work, pchan chan<- Progress) {}),
+			1, 5, OverflowBlock, WithEnqueueTransforms(enrich), WithAudit())
+		defer close(q.Progress())
+
+		var (
+			lock   sync.Mutex
+			events []Event
+		)
+		go func() {
+			for p := range q.Progress() {
+				if p.Type == ProgressEvent {
+					lock.Lock()
+					events = append(events, p.Data.(Event))
+					lock.Unlock()
+				}
+			}
+		}()
+
+		c.So(q.Add(NewWork(map[string]any{"name": "hello"})), ShouldBeTrue)
+		c.So(q.Add(NewWork(map[string]any{"name": "world", "region": "us-east"})), ShouldBeTrue) // unchanged
+		q.Done()
+		<-q.IsDone()
+		c.So(waitFor(time.Second, func() bool {
+			lock.Lock()
+			defer lock.Unlock()
+			return len(events) == 1
+		}), ShouldBeTrue)
+
+		lock.Lock()
+		defer lock.Unlock()
+		c.So(events, ShouldHaveLength, 1)
+		c.So(events[0].Name, ShouldEqual, EventAudit)
+		diff := events[0].Data.(WorkDiff)
+		c.So(diff.Added, ShouldResemble, map[string]any{"region": "us-east"})
+		c.So(diff.Removed, ShouldBeEmpty)
+		c.So(diff.Changed, ShouldBeEmpty)
+	})
+}
+
+func Test_QueuedJobDropOldest(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	Convey("When a QueuedJob with a DropOldest policy is full, the oldest queued Work is dropped for the new.", t, func(c C) {
+		var (
+			lock sync.Mutex
+			seen []int
+		)
+		gate := make(chan struct{})
+
+		q := NewQueuedJob(NewJob(func(id any, work Work, pchan chan<- Progress) {
+			<-gate
+			lock.Lock()
+			defer lock.Unlock()
+			seen = append(seen, work.GetInt("n"))
+		}), 1, 2, OverflowDropOldest)
+		defer close(q.Progress())
+		go ProgressLogger(disco, false, nil, q.Progress(), nil)
+
+		its := 10
+		for i := range its {
+			c.So(q.Add(NewWork(map[string]any{"n": i})), ShouldBeTrue)
+		}
+
+		close(gate)
+		q.Done()
+		<-q.IsDone()
+
+		lock.Lock()
+		defer lock.Unlock()
+		c.So(len(seen), ShouldBeBetweenOrEqual, 2, 4)
+		c.So(seen[len(seen)-1], ShouldEqual, its-1) // the newest survives
+	})
+}
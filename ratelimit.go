@@ -0,0 +1,82 @@
+package racket
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenBucket is a simple goro-safe token bucket rate limiter, that starts full.
+type tokenBucket struct {
+	lock   sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a tokenBucket allowing perSecond tokens per second, with bursts of up to burst.
+func newTokenBucket(perSecond float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:   perSecond,
+		burst:  float64(max(burst, 1)),
+		tokens: float64(max(burst, 1)),
+		last:   time.Now(),
+	}
+}
+
+// wait blocks until a token is available, returning false if the context is done first.
+func (b *tokenBucket) wait(ctx context.Context) bool {
+	b.lock.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens-- // reserve a token, possibly going into debt
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.lock.Unlock()
+
+	if delay <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		// give the reservation back
+		b.lock.Lock()
+		b.tokens++
+		b.lock.Unlock()
+		return false
+	}
+}
+
+// bucketSet is a goro-safe set of tokenBuckets, lazily created per key.
+type bucketSet struct {
+	lock      sync.Mutex
+	perSecond float64
+	buckets   map[any]*tokenBucket
+}
+
+// newBucketSet returns a bucketSet, whose tokenBuckets allow perSecond tokens per second.
+func newBucketSet(perSecond float64) *bucketSet {
+	return &bucketSet{
+		perSecond: perSecond,
+		buckets:   make(map[any]*tokenBucket),
+	}
+}
+
+// get returns the tokenBucket for the key, creating it if needed.
+func (s *bucketSet) get(key any) *tokenBucket {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = newTokenBucket(s.perSecond, 1)
+		s.buckets[key] = b
+	}
+	return b
+}
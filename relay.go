@@ -0,0 +1,52 @@
+package racket
+
+import (
+	"sync/atomic"
+)
+
+// relay forwards every item from in to out, until in is closed and the buffer is drained, so senders on in
+// don't wait on whoever receives from out. If bound is greater than 0, at most bound items are buffered, and
+// when the buffer is full the policy decides: OverflowBlock stops receiving (so senders block), OverflowDrop
+// discards the new item, and OverflowDropOldest discards the oldest buffered item to make room, counting
+// discards in dropped. out is not closed.
+func relay[T any](in <-chan T, out chan<- T, bound int, policy OverflowPolicy, dropped *atomic.Int64) {
+	var buffer []T
+
+	for in != nil || len(buffer) > 0 {
+		var (
+			recv <-chan T
+			send chan<- T
+			next T
+		)
+
+		if in != nil && (bound <= 0 || len(buffer) < bound || policy != OverflowBlock) {
+			recv = in
+		}
+		if len(buffer) > 0 {
+			send = out
+			next = buffer[0]
+		}
+
+		select {
+		case v, ok := <-recv:
+			if !ok {
+				in = nil
+				continue
+			}
+			if bound > 0 && len(buffer) >= bound {
+				dropped.Add(1)
+				if policy != OverflowDropOldest {
+					continue
+				}
+				var zero T
+				buffer[0] = zero
+				buffer = buffer[1:]
+			}
+			buffer = append(buffer, v)
+		case send <- next:
+			var zero T
+			buffer[0] = zero // don't pin it
+			buffer = buffer[1:]
+		}
+	}
+}
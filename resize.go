@@ -0,0 +1,87 @@
+package racket
+
+import (
+	"fmt"
+
+	"github.com/cognusion/semaphore"
+)
+
+// Resize changes the maximum number of concurrent workers of a running Job. As a Semaphore's size is fixed,
+// the lock and the slots are replaced: free slots under the new maximum carry over, and growing adds the
+// missing ones. Slots over the new maximum that are busy are retired as their workers return (see release).
+func (j *defaultJob) Resize(maxWorkers int) {
+	if maxWorkers < 1 {
+		panic(fmt.Sprintf("racket: Resize called with a maxWorkers of %d, less than 1", maxWorkers))
+	}
+	if j.pooled {
+		j.logger.Println("[RACKET] WARNING: Resize has no effect on a Job WithPooledWorkers")
+		return
+	}
+
+	j.sizeLock.Lock()
+	defer j.sizeLock.Unlock()
+
+	if j.slots == nil || maxWorkers == j.maxWorkers {
+		return
+	}
+
+	free := make(map[int]struct{}, len(j.slots))
+	for len(j.slots) > 0 {
+		free[<-j.slots] = struct{}{}
+	}
+
+	lock := semaphore.NewSemaphore(maxWorkers)
+	slots := make(chan int, maxWorkers)
+	for slot := range max(maxWorkers, j.maxWorkers) {
+		_, isFree := free[slot]
+		_, isRetiring := j.retiring[slot]
+		switch {
+		case slot >= maxWorkers:
+			if !isFree {
+				j.retiring[slot] = struct{}{} // busy, and not coming back
+			}
+		case isFree || (slot >= j.maxWorkers && !isRetiring):
+			slots <- slot
+		}
+		// Otherwise the slot is busy, and its worker returns it to the new slots.
+	}
+
+	j.lock = &lock
+	j.slots = slots
+	j.maxWorkers = maxWorkers
+
+	select {
+	case j.resized <- struct{}{}:
+	default: // the Supervisor already has a wake-up pending
+	}
+}
+
+// claim returns whether the Supervisor may spawn a worker for the slot it received, which it may not if Resize
+// retired the slot after the Supervisor had picked up the old slots.
+func (j *defaultJob) claim(slot int) bool {
+	j.sizeLock.Lock()
+	defer j.sizeLock.Unlock()
+
+	if _, ok := j.retiring[slot]; ok {
+		delete(j.retiring, slot)
+		if slot >= j.maxWorkers {
+			return false
+		}
+	}
+	return true
+}
+
+// release returns the slot of a worker that is done, unless Resize retired it. The slots are unique, and the
+// slots channel holds up to maxWorkers of them, so this won't block.
+func (j *defaultJob) release(slot int) {
+	j.sizeLock.Lock()
+	defer j.sizeLock.Unlock()
+
+	if _, ok := j.retiring[slot]; ok {
+		delete(j.retiring, slot)
+		if slot >= j.maxWorkers {
+			return
+		}
+	}
+	j.slots <- slot
+}
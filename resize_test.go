@@ -0,0 +1,111 @@
+package racket
+
+import (
+	"io"
+	"log"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_JobResize(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	Convey("When a running Job is Resized up, more workers run concurrently.", t, func() {
+		gate := make(chan struct{})
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			<-gate
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(nil)
+		So(waitFor(time.Second, func() bool { return j.Stats().BusyWorkers == 1 }), ShouldBeTrue)
+
+		j.Resize(4)
+		for range 3 {
+			wchan <- NewWork(nil) // with one worker, these would block until the gate
+		}
+		So(waitFor(time.Second, func() bool { return j.Stats().BusyWorkers == 4 }), ShouldBeTrue)
+
+		close(gate)
+		done()
+		<-j.IsDone()
+		close(pchan)
+		So(j.CompletedCount(), ShouldEqual, 4)
+	})
+
+	Convey("When a running Job is Resized down, fewer workers run concurrently, once the busy ones are done.", t, func() {
+		var (
+			current atomic.Int64
+			peak    atomic.Int64
+		)
+		gate := make(chan struct{})
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			if work.GetBool("hold") {
+				<-gate
+				return
+			}
+			n := current.Add(1)
+			defer current.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(4, wchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		for range 4 {
+			wchan <- NewWork(map[string]any{"hold": true})
+		}
+		So(waitFor(time.Second, func() bool { return j.Stats().BusyWorkers == 4 }), ShouldBeTrue)
+
+		j.Resize(1)
+		close(gate)
+		for range 20 {
+			wchan <- NewWork(nil)
+		}
+		done()
+		<-j.IsDone()
+		close(pchan)
+
+		So(j.CompletedCount(), ShouldEqual, 24)
+		So(peak.Load(), ShouldEqual, 1)
+
+		Convey("... and Resized back up, the retired slots are reused.", func() {
+			So(j.Reset(), ShouldBeNil)
+			peak.Store(0)
+			wchan := make(chan Work)
+			pchan, done := j.Supervisor(4, wchan)
+			go ProgressLogger(disco, false, nil, pchan, nil)
+
+			j.Resize(2)
+			j.Resize(3)
+			for range 20 {
+				wchan <- NewWork(nil)
+			}
+			done()
+			<-j.IsDone()
+			close(pchan)
+
+			So(j.CompletedCount(), ShouldEqual, 20)
+			So(peak.Load(), ShouldBeBetweenOrEqual, 1, 3)
+		})
+	})
+
+	Convey("When a Job is Resized to less than 1, it panics.", t, func() {
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {})
+		So(func() { j.Resize(0) }, ShouldPanicWith, "racket: Resize called with a maxWorkers of 0, less than 1")
+	})
+}
@@ -0,0 +1,166 @@
+package racket
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// ResultSuccess is a ResultStatus when the WorkerFunc completed without error.
+// ResultFailed is a ResultStatus when the WorkerFunc returned an error (or panicked), after any retries.
+// ResultSkipped is a ResultStatus when the Work was never run, e.g. because the Job was already cancelled.
+// ResultTimedOut is a ResultStatus when the WorkerFunc failed because a deadline expired, after any retries.
+// ResultCancelled is a ResultStatus when the Job was cancelled while the WorkerFunc was running.
+const (
+	ResultSuccess ResultStatus = iota
+	ResultFailed
+	ResultSkipped
+	ResultTimedOut
+	ResultCancelled
+)
+
+// ResultStatus is one of the constant statuses of a Result.
+type ResultStatus int
+
+// String returns the stringified version of the status name
+func (s ResultStatus) String() string {
+	switch s {
+	case ResultSuccess:
+		return "Success"
+	case ResultFailed:
+		return "Failed"
+	case ResultSkipped:
+		return "Skipped"
+	case ResultTimedOut:
+		return "TimedOut"
+	case ResultCancelled:
+		return "Cancelled"
+	default:
+		return ""
+	}
+}
+
+// Result is the outcome of a unit of Work: the Value (if the worker produces one) and error, the Status,
+// the originating Work, how many Attempts were made, and the Duration of all of them.
+// Context is already cancelled if the Work was skipped or cancelled, so a downstream stage can pass it along
+// (or check Cancelled) to skip work for items cancelled upstream. Unlike the Job's own context, it stays
+// uncancelled for items that weren't, after the Job is done.
+type Result struct {
+	Value    any
+	Err      error
+	Status   ResultStatus
+	Work     Work
+	Attempts int
+	Duration time.Duration
+	Context  context.Context
+}
+
+// Cancelled returns true if the Result's Context is cancelled, i.e. the Work was cancelled or skipped upstream.
+func (r Result) Cancelled() bool {
+	return r.Context != nil && r.Context.Err() != nil
+}
+
+// Summary is a rollup of a Job run, sent as the final Progress by Shutdown, see WithSummary.
+type Summary struct {
+	// Completed is how many units of Work the workers finished, whatever the outcome, including cache hits.
+	Completed int64 `json:"completed"`
+	// Succeeded is how many finished without error, including cache hits.
+	Succeeded int64 `json:"succeeded"`
+	// Failed is how many finished with an error, after any retries.
+	Failed int64 `json:"failed"`
+	// Duration is how long the Job ran, from Supervisor to Shutdown.
+	Duration time.Duration `json:"duration"`
+}
+
+// ResultJob is a Job whose workers each return a value of T, delivered on Results.
+type ResultJob[T any] interface {
+	Job
+	// Results returns the channel of the values of successful Work, in the order they complete. It is closed once
+	// the Job is done, and must be consumed, or the workers block. The errors of failed Work are in Errors.
+	Results() <-chan T
+}
+
+// NewJobResult consumes a func that returns a value of T to accomplish Work, and returns a ResultJob.
+// WithResults is used internally, so shouldn't be in the opts. It panics if workerFunc is nil, rather than at the
+// first dispatch.
+func NewJobResult[T any](workerFunc func(id any, work Work) (T, error), opts ...JobOption) ResultJob[T] {
+	if workerFunc == nil {
+		panic("racket: NewJobResult called with a nil func")
+	}
+
+	in := make(chan Result)
+	j := newJob(func(_ context.Context, id any, work Work, _ chan<- Progress) (any, error) {
+		return workerFunc(id, work)
+	}, append(slices.Clone(opts), WithResults(in))...) // not into the caller's backing array
+
+	return &resultJob[T]{
+		defaultJob: j,
+		in:         in,
+		out:        make(chan T),
+	}
+}
+
+// resultJob is a defaultJob that forwards the values of its successful Results to out.
+type resultJob[T any] struct {
+	*defaultJob
+	in  chan Result
+	out chan T
+}
+
+// Results returns the channel of values.
+func (r *resultJob[T]) Results() <-chan T {
+	return r.out
+}
+
+// Supervisor is defaultJob.Supervisor, also forwarding the values to Results.
+func (r *resultJob[T]) Supervisor(maxWorkers int, workChan chan Work) (progressChan chan Progress, doneFunc func()) {
+	progressChan, doneFunc = r.defaultJob.Supervisor(maxWorkers, workChan)
+	go r.forward(r.finished, r.resultsRelayed, r.out)
+	return progressChan, doneFunc
+}
+
+// SupervisorContext is defaultJob.SupervisorContext, also forwarding the values to Results.
+func (r *resultJob[T]) SupervisorContext(ctx context.Context, maxWorkers int, workChan chan Work) (progressChan chan Progress, doneFunc func()) {
+	progressChan, doneFunc = r.defaultJob.SupervisorContext(ctx, maxWorkers, workChan)
+	go r.forward(r.finished, r.resultsRelayed, r.out)
+	return progressChan, doneFunc
+}
+
+// SupervisorPriority is defaultJob.SupervisorPriority, also forwarding the values to Results.
+func (r *resultJob[T]) SupervisorPriority(maxWorkers int) (submit func(PriorityWork), progressChan chan Progress, doneFunc func()) {
+	submit, progressChan, doneFunc = r.defaultJob.SupervisorPriority(maxWorkers)
+	go r.forward(r.finished, r.resultsRelayed, r.out)
+	return submit, progressChan, doneFunc
+}
+
+// Reset is defaultJob.Reset, also replacing the closed Results channel.
+func (r *resultJob[T]) Reset() error {
+	if err := r.defaultJob.Reset(); err != nil {
+		return err
+	}
+	r.out = make(chan T)
+	return nil
+}
+
+// forward sends the values of successful Results to out, until the workers have finished, and any buffered Results
+// (see WithResultBuffer) have been relayed, then closes out.
+func (r *resultJob[T]) forward(finished, resultsRelayed chan struct{}, out chan T) {
+	defer close(out)
+
+	// Every Result is sent before its worker finishes, so once finished (and relayed), there are no more.
+	sent := finished
+	if resultsRelayed != nil {
+		sent = resultsRelayed
+	}
+	for {
+		select {
+		case res := <-r.in:
+			if res.Status == ResultSuccess {
+				v, _ := res.Value.(T) // a nil value of an interface T isn't a T
+				out <- v
+			}
+		case <-sent:
+			return
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package racket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// collectResults runs the Work through the Job, and returns the Results sent.
+// If before is non-nil, it is called after the Supervisor is hired, and before any Work is sent.
+func collectResults(job func(results chan<- Result) Job, items []Work, before func(Job)) []Result {
+	return collectResultsProgress(job, items, before, nil)
+}
+
+// collectResultsProgress is collectResults, also calling onProgress, if not nil, with every Progress.
+func collectResultsProgress(job func(results chan<- Result) Job, items []Work, before func(Job), onProgress func(Progress)) []Result {
+	disco := log.New(io.Discard, "", 0)
+	results := make(chan Result)
+	j := job(results)
+
+	wchan := make(chan Work)
+	pchan, _ := j.Supervisor(2, wchan)
+	if onProgress == nil {
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+	} else {
+		// Wait for onProgress to finish with the last Progress before returning.
+		progressed := make(chan struct{})
+		defer func() {
+			close(pchan)
+			<-progressed
+		}()
+		go func() {
+			defer close(progressed)
+			for p := range pchan {
+				onProgress(p)
+			}
+		}()
+	}
+
+	var rs []Result
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for r := range results {
+			rs = append(rs, r)
+		}
+	}()
+
+	if before != nil {
+		before(j)
+	}
+	for _, w := range items {
+		wchan <- w
+	}
+	// Let any pending retries finish, so Attempts are deterministic.
+	j.DoneAllowRetries()
+	<-j.IsDone()
+	close(results)
+	<-collected
+
+	return rs
+}
+
+func Test_Result(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When Work succeeds, the Result is a Success, with details", t, func() {
+		rs := collectResults(func(results chan<- Result) Job {
+			return NewJob(func(id any, work Work, pchan chan<- Progress) {
+				time.Sleep(10 * time.Millisecond)
+			}, WithResults(results))
+		}, []Work{NewWork(map[string]any{"n": 1})}, nil)
+
+		So(rs, ShouldHaveLength, 1)
+		So(rs[0].Status, ShouldEqual, ResultSuccess)
+		So(rs[0].Status.String(), ShouldEqual, "Success")
+		So(rs[0].Err, ShouldBeNil)
+		So(rs[0].Work.GetInt("n"), ShouldEqual, 1)
+		So(rs[0].Attempts, ShouldEqual, 1)
+		So(rs[0].Duration, ShouldBeGreaterThanOrEqualTo, 10*time.Millisecond)
+	})
+
+	Convey("When Work fails after retries, the Result is Failed", t, func() {
+		boom := errors.New("boom")
+		rs := collectResults(func(results chan<- Result) Job {
+			return NewJobErr(func(id any, work Work) error {
+				return boom
+			}, WithResults(results), WithRetry(2, nil))
+		}, []Work{NewWork(nil)}, nil)
+
+		So(rs, ShouldHaveLength, 1)
+		So(rs[0].Status, ShouldEqual, ResultFailed)
+		So(rs[0].Err, ShouldEqual, boom)
+		So(rs[0].Attempts, ShouldEqual, 2)
+	})
+
+	Convey("When Work times out, the Result is TimedOut", t, func() {
+		rs := collectResults(func(results chan<- Result) Job {
+			return NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+				<-ctx.Done()
+			}, WithResults(results), WithItemTimeout(10*time.Millisecond))
+		}, []Work{NewWork(nil)}, nil)
+
+		So(rs, ShouldHaveLength, 1)
+		So(rs[0].Status, ShouldEqual, ResultTimedOut)
+		So(rs[0].Err, ShouldBeError)
+	})
+
+	Convey("When the Job is cancelled while Work is running, the Result is Cancelled", t, func() {
+		rs := collectResults(func(results chan<- Result) Job {
+			return NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+				if work.GetBool("slow") {
+					<-ctx.Done()
+				}
+			}, WithResults(results), StopAfterSuccesses(1))
+		}, []Work{NewWork(map[string]any{"slow": true}), NewWork(nil)}, nil)
+
+		So(rs, ShouldHaveLength, 2)
+		So(rs[0].Status, ShouldEqual, ResultSuccess)
+		So(rs[1].Status, ShouldEqual, ResultCancelled)
+	})
+
+	Convey("When the Job is cancelled before Work is run, the Result is Skipped", t, func() {
+		rs := collectResults(func(results chan<- Result) Job {
+			return NewJob(func(id any, work Work, pchan chan<- Progress) {}, WithResults(results))
+		}, []Work{NewWork(nil)}, func(j Job) {
+			j.(*defaultJob).cancel()
+		})
+
+		So(rs, ShouldHaveLength, 1)
+		So(rs[0].Status, ShouldEqual, ResultSkipped)
+		So(rs[0].Status.String(), ShouldEqual, "Skipped")
+		So(rs[0].Attempts, ShouldEqual, 0)
+	})
+
+	Convey("When an upstream item is cancelled, a downstream stage observes it via the Result", t, func() {
+		upstream := collectResults(func(results chan<- Result) Job {
+			return NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+				if work.GetBool("slow") {
+					<-ctx.Done()
+				}
+			}, WithResults(results), StopAfterSuccesses(1))
+		}, []Work{NewWork(map[string]any{"slow": true, "n": 1}), NewWork(map[string]any{"n": 2})}, nil)
+		So(upstream, ShouldHaveLength, 2)
+
+		// The upstream Job is done, but only the cancelled item's Context is cancelled
+		So(upstream[0].Cancelled(), ShouldBeFalse)
+		So(upstream[0].Context.Err(), ShouldBeNil)
+		So(upstream[1].Cancelled(), ShouldBeTrue)
+		So(context.Cause(upstream[1].Context), ShouldEqual, context.Canceled)
+
+		var items []Work
+		for _, r := range upstream {
+			items = append(items, NewWork(map[string]any{"upstream": r}))
+		}
+
+		var (
+			lock sync.Mutex
+			ran  []int
+		)
+		downstream := collectResults(func(results chan<- Result) Job {
+			return NewJobErr(func(id any, work Work) error {
+				r := work.Get("upstream").(Result)
+				if r.Cancelled() {
+					return context.Cause(r.Context)
+				}
+				lock.Lock()
+				defer lock.Unlock()
+				ran = append(ran, r.Work.GetInt("n"))
+				return nil
+			}, WithResults(results))
+		}, items, nil)
+
+		So(downstream, ShouldHaveLength, 2)
+		So(ran, ShouldResemble, []int{2})
+	})
+}
+
+func Test_ResultBuffer(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 50
+	bound := 5
+
+	Convey("When Results are consumed slowly through a bounded buffer, workers don't stall, and the oldest are dropped and counted.", t, func(c C) {
+		results := make(chan Result)
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {}, WithResults(results), WithResultBuffer(bound))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		var rs []Result
+		collected := make(chan struct{})
+		stalled := make(chan struct{})
+		go func() {
+			defer close(collected)
+			<-stalled // lagging...
+			for r := range results {
+				rs = append(rs, r)
+			}
+		}()
+
+		for i := range its {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+		done()
+
+		// Nobody is consuming Results, yet all of the Work gets done.
+		c.So(waitFor(time.Second, func() bool { return j.CompletedCount() == int64(its) }), ShouldBeTrue)
+
+		close(stalled)
+		<-j.IsDone()
+		close(results)
+		<-collected
+
+		c.So(j.(JobDiagnostics).ResultsDropped(), ShouldBeGreaterThan, 0)
+		c.So(int64(len(rs))+j.(JobDiagnostics).ResultsDropped(), ShouldEqual, its)
+		c.So(len(rs), ShouldBeBetweenOrEqual, bound, bound+1)    // the buffer, and perhaps one in hand
+		c.So(rs[len(rs)-1].Work.GetInt("n"), ShouldEqual, its-1) // the newest survive
+	})
+}
+
+func Test_JobAttempt(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a WorkerFuncAttempt only succeeds on attempt 2, it's retried and succeeds", t, func() {
+		var (
+			lock     sync.Mutex
+			attempts []int
+		)
+		fallback := errors.New("primary endpoint down")
+
+		rs := collectResults(func(results chan<- Result) Job {
+			return NewJobAttempt(func(id any, work Work, attempt int) error {
+				lock.Lock()
+				defer lock.Unlock()
+				attempts = append(attempts, attempt)
+				if attempt < 2 {
+					return fallback
+				}
+				return nil
+			}, WithResults(results), WithRetry(3, nil))
+		}, []Work{NewWork(nil)}, nil)
+
+		So(rs, ShouldHaveLength, 1)
+		So(rs[0].Status, ShouldEqual, ResultSuccess)
+		So(rs[0].Attempts, ShouldEqual, 2)
+		So(attempts, ShouldResemble, []int{1, 2})
+	})
+}
+
+func Test_ResultJob(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+	its := 100
+
+	Convey("When a ResultJob's workers return values, Results has them all, and is closed when the Job is done.", t, func() {
+		j := NewJobResult(func(id any, work Work) (int, error) {
+			n := work.GetInt("n")
+			if n < 0 {
+				return 0, fmt.Errorf("negative %d", n)
+			}
+			return n * n, nil
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(8, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		var got []int
+		collected := make(chan struct{})
+		go func() {
+			defer close(collected)
+			for v := range j.Results() {
+				got = append(got, v)
+			}
+		}()
+
+		for i := range its {
+			wchan <- NewWork(map[string]any{"n": i})
+		}
+		wchan <- NewWork(map[string]any{"n": -1})
+		done()
+		<-j.IsDone()
+		<-collected
+
+		expected := make([]int, its)
+		for i := range expected {
+			expected[i] = i * i
+		}
+		sort.Ints(got)
+		So(got, ShouldResemble, expected)
+		So(j.Errors(), ShouldHaveLength, 1)
+		So(j.Errors()[0], ShouldBeError, "negative -1")
+	})
+
+	Convey("When a ResultJob of an interface type returns a nil value, it is a nil Result.", t, func() {
+		j := NewJobResult(func(id any, work Work) (any, error) {
+			return nil, nil
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		var got []any
+		collected := make(chan struct{})
+		go func() {
+			defer close(collected)
+			for v := range j.Results() {
+				got = append(got, v)
+			}
+		}()
+
+		wchan <- NewWork(nil)
+		done()
+		<-j.IsDone()
+		<-collected
+		So(got, ShouldResemble, []any{nil})
+	})
+
+	Convey("When a ResultJob is made, the caller's options are left alone.", t, func() {
+		opts := make([]JobOption, 1, 2)
+		opts[0] = WithCompletionLog()
+		NewJobResult(func(id any, work Work) (int, error) { return 0, nil }, opts...)
+		So(opts[:2][1], ShouldBeNil)
+	})
+
+	Convey("When a ResultJob has a nil func, it panics.", t, func() {
+		So(func() { NewJobResult[int](nil) }, ShouldPanicWith, "racket: NewJobResult called with a nil func")
+	})
+}
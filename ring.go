@@ -0,0 +1,56 @@
+package racket
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ProgressRing is an in-memory ring of the most recent lines logged by a ProgressLogger (see LogToRing), e.g. for
+// a status endpoint. It is safe for concurrent use.
+type ProgressRing struct {
+	lock  sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+// NewProgressRing returns a ProgressRing that retains the last size lines. It panics if size is less than 1.
+func NewProgressRing(size int) *ProgressRing {
+	if size < 1 {
+		panic(fmt.Sprintf("racket: NewProgressRing called with a size of %d, less than 1", size))
+	}
+	return &ProgressRing{
+		lines: make([]string, size),
+	}
+}
+
+// add appends the line, overwriting the oldest if the ring is full.
+func (r *ProgressRing) add(line string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % len(r.lines)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// RecentLines returns a copy of the retained lines, oldest first.
+func (r *ProgressRing) RecentLines() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.full {
+		return append([]string{}, r.lines[:r.next]...)
+	}
+	return append(append([]string{}, r.lines[r.next:]...), r.lines[:r.next]...)
+}
+
+// LogToRing is a LoggerOption that makes ProgressLogger also add each line it logs, without the trailing newline,
+// to the ProgressRing.
+func LogToRing(ring *ProgressRing) LoggerOption {
+	return func(c *loggerConfig) {
+		c.ring = ring
+	}
+}
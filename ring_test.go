@@ -0,0 +1,45 @@
+package racket
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ProgressRing(t *testing.T) {
+
+	Convey("When a ProgressLogger logs more lines than the ring holds, only the last are retained, oldest first.", t, func() {
+		ring := NewProgressRing(3)
+		So(ring.RecentLines(), ShouldBeEmpty)
+
+		pchan := make(chan Progress, 5)
+		for i := range 5 {
+			pchan <- PMessagef("message %d", i)
+		}
+		close(pchan)
+		ProgressLogger(log.New(io.Discard, "", 0), true, nil, pchan, nil, LogToRing(ring))
+
+		So(ring.RecentLines(), ShouldResemble, []string{
+			"[PROGRESS] message 2",
+			"[PROGRESS] message 3",
+			"[PROGRESS] message 4",
+		})
+	})
+
+	Convey("When a ProgressRing isn't yet full, it has just what was logged.", t, func() {
+		ring := NewProgressRing(3)
+
+		pchan := make(chan Progress, 1)
+		pchan <- PErrorf("oops")
+		close(pchan)
+		ProgressLogger(log.New(io.Discard, "", 0), false, nil, pchan, nil, LogToRing(ring), LogJSON())
+
+		So(ring.RecentLines(), ShouldResemble, []string{`{"type":"ProgressError","data":"oops"}`})
+	})
+
+	Convey("When a ProgressRing is made too small, it panics.", t, func() {
+		So(func() { NewProgressRing(0) }, ShouldPanicWith, "racket: NewProgressRing called with a size of 0, less than 1")
+	})
+}
@@ -0,0 +1,57 @@
+package racket
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ProgressLoggerSlog is ProgressLogger for structured logging: it loops over a Progress channel, logging each to
+// the slog.Logger at a level by ProgressType, with a "progress_type" attribute, and the typed Data as attributes.
+// Errors and timeouts are logged at Error, with an "error" attribute, and then, if errf is non-nil, it is called with
+// the error: Panic'ing or Exit'ing is allowed. Warnings are logged at Warn; messages, skips (with a "reason"), and
+// events (with an "event" name and "data") at Info; and debug, and the numeric types (with "data"), at Debug, so the
+// handler's level decides the verbosity. Stamped Progress (see Progress.From) also has a "worker_id" and a
+// "progress_time" attribute. ProgressBar-related Progress will be sent to the barChan as-is.
+func ProgressLoggerSlog(logger *slog.Logger, errf ProgressErrorFunc, progressChan <-chan Progress, barChan chan Progress) {
+	ctx := context.Background()
+
+	for p := range progressChan {
+		as := []slog.Attr{slog.String("progress_type", p.Type.String())}
+		if p.WorkerID != nil {
+			as = append(as, slog.Any("worker_id", p.WorkerID))
+		}
+		if !p.Time.IsZero() {
+			as = append(as, slog.Time("progress_time", p.Time))
+		}
+
+		switch p.Type {
+		case ProgressError, ProgressTimeout:
+			err := p.Data.(error)
+			logger.LogAttrs(ctx, slog.LevelError, err.Error(), append(as, slog.Any("error", err))...)
+
+			if errf != nil {
+				// callback
+				errf(err)
+			}
+		case ProgressWarning:
+			logger.LogAttrs(ctx, slog.LevelWarn, p.Data.(string), as...)
+		case ProgressMessage:
+			logger.LogAttrs(ctx, slog.LevelInfo, p.Data.(string), as...)
+		case ProgressSkipped:
+			logger.LogAttrs(ctx, slog.LevelInfo, "skipped", append(as, slog.String("reason", p.Data.(string)))...)
+		case ProgressEvent:
+			e := p.Data.(Event)
+			logger.LogAttrs(ctx, slog.LevelInfo, "event", append(as, slog.String("event", e.Name), slog.Any("data", e.Data))...)
+		case ProgressDebug:
+			logger.LogAttrs(ctx, slog.LevelDebug, p.Data.(string), as...)
+		case ProgressUpdate, ProgressEstimate, ProgressRemaining, ProgressPercent:
+			logger.LogAttrs(ctx, slog.LevelDebug, p.Type.String(), append(as, slog.Any("data", p.Data))...)
+			if barChan != nil {
+				barChan <- p
+			}
+		default:
+			// Always log weird shit.
+			logger.LogAttrs(ctx, slog.LevelWarn, "unknown progress", slog.Int("progress_type", int(p.Type)), slog.Any("data", p.Data))
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package racket
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// recordHandler is a slog.Handler that captures the records at or above its level.
+type recordHandler struct {
+	lock    sync.Mutex
+	level   slog.Level
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordHandler) WithGroup(string) slog.Handler      { return h }
+
+// attrs returns the attributes of the record by key.
+func attrs(r slog.Record) map[string]any {
+	m := make(map[string]any)
+	r.Attrs(func(a slog.Attr) bool {
+		m[a.Key] = a.Value.Any()
+		return true
+	})
+	return m
+}
+
+func Test_ProgressLoggerSlog(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a ProgressLoggerSlog logs Progress, each is a record at the level of its type, with typed attributes.", t, func() {
+		h := &recordHandler{level: slog.LevelDebug}
+		e := errors.New("Error!")
+		var errs []error
+
+		pchan := make(chan Progress, 8)
+		bchan := make(chan Progress, 8)
+		pchan <- Progress{Type: ProgressError, Data: e}
+		pchan <- PWarnf("Warning!")
+		pchan <- PMessagef("Hello")
+		pchan <- PSkipped(SkipCacheHit)
+		pchan <- PEvent(EventAudit, 1)
+		pchan <- PDebugf("Debug!")
+		pchan <- PUpdate(42)
+		pchan <- PEstimate(100)
+		close(pchan)
+
+		ProgressLoggerSlog(slog.New(h), func(err error) { errs = append(errs, err) }, pchan, bchan)
+		close(bchan)
+
+		So(errs, ShouldResemble, []error{e})
+		var bar []Progress
+		for p := range bchan {
+			bar = append(bar, p)
+		}
+		So(bar, ShouldResemble, []Progress{PUpdate(42), PEstimate(100)})
+
+		So(h.records, ShouldHaveLength, 8)
+		expected := []struct {
+			level slog.Level
+			msg   string
+			attrs map[string]any
+		}{
+			{slog.LevelError, "Error!", map[string]any{"progress_type": "ProgressError", "error": e}},
+			{slog.LevelWarn, "Warning!", map[string]any{"progress_type": "ProgressWarning"}},
+			{slog.LevelInfo, "Hello", map[string]any{"progress_type": "ProgressMessage"}},
+			{slog.LevelInfo, "skipped", map[string]any{"progress_type": "ProgressSkipped", "reason": SkipCacheHit}},
+			{slog.LevelInfo, "event", map[string]any{"progress_type": "ProgressEvent", "event": EventAudit, "data": int64(1)}},
+			{slog.LevelDebug, "Debug!", map[string]any{"progress_type": "ProgressDebug"}},
+			{slog.LevelDebug, "ProgressUpdate", map[string]any{"progress_type": "ProgressUpdate", "data": int64(42)}},
+			{slog.LevelDebug, "ProgressEstimate", map[string]any{"progress_type": "ProgressEstimate", "data": int64(100)}},
+		}
+		for i, r := range h.records {
+			So(r.Level, ShouldEqual, expected[i].level)
+			So(r.Message, ShouldEqual, expected[i].msg)
+			So(attrs(r), ShouldResemble, expected[i].attrs)
+		}
+
+		Convey("... and stamped Progress has the worker ID and time attributes.", func() {
+			h := &recordHandler{level: slog.LevelDebug}
+			when := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+			pchan := make(chan Progress, 1)
+			pchan <- Progress{Type: ProgressMessage, Data: "Hello", WorkerID: 3, Time: when}
+			close(pchan)
+
+			ProgressLoggerSlog(slog.New(h), nil, pchan, nil)
+			So(h.records, ShouldHaveLength, 1)
+			So(attrs(h.records[0]), ShouldResemble, map[string]any{"progress_type": "ProgressMessage", "worker_id": int64(3), "progress_time": when})
+		})
+
+		Convey("... and the handler's level drops the debug.", func() {
+			h := &recordHandler{level: slog.LevelInfo}
+			pchan := make(chan Progress, 2)
+			pchan <- PDebugf("Debug!")
+			pchan <- PMessagef("Hello")
+			close(pchan)
+
+			ProgressLoggerSlog(slog.New(h), nil, pchan, nil)
+			So(h.records, ShouldHaveLength, 1)
+			So(h.records[0].Message, ShouldEqual, "Hello")
+		})
+	})
+}
@@ -0,0 +1,37 @@
+package racket
+
+import "fmt"
+
+// Split expands the Work into sub-Work with the splitter, and sends each to the work channel in turn, returning how
+// many were sent. Each sub-Work is dispatched, and completed (see CompletedCount and WithResults), individually.
+func Split(workChan chan<- Work, work Work, splitter func(Work) []Work) int {
+	subs := splitter(work)
+	for _, sub := range subs {
+		workChan <- sub
+	}
+	return len(subs)
+}
+
+// RangeSplitter returns a splitter for Split, that partitions Work describing the inclusive range of integers from
+// the fromKey to the toKey into chunks of up to chunkSize, each a Clone of the Work with its own fromKey and toKey.
+// An empty range (to is less than from) splits into nothing. It panics if chunkSize is less than 1.
+func RangeSplitter(fromKey, toKey string, chunkSize int) func(Work) []Work {
+	if chunkSize < 1 {
+		panic(fmt.Sprintf("racket: RangeSplitter called with a chunkSize of %d, less than 1", chunkSize))
+	}
+
+	return func(work Work) []Work {
+		var (
+			from = work.GetInt64(fromKey)
+			to   = work.GetInt64(toKey)
+			subs []Work
+		)
+		for start := from; start <= to; start += int64(chunkSize) {
+			sub := work.Clone()
+			sub.Set(fromKey, start)
+			sub.Set(toKey, min(start+int64(chunkSize)-1, to))
+			subs = append(subs, sub)
+		}
+		return subs
+	}
+}
@@ -0,0 +1,59 @@
+package racket
+
+import (
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Split(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a range of Work is Split into chunks, each chunk is its own WorkerFunc invocation.", t, func() {
+		var (
+			lock   sync.Mutex
+			ranges [][2]int64
+		)
+
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			lock.Lock()
+			defer lock.Unlock()
+			ranges = append(ranges, [2]int64{work.GetInt64("from"), work.GetInt64("to")})
+		})
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(4, wchan)
+		go ProgressLogger(log.New(io.Discard, "", 0), false, nil, pchan, nil)
+
+		n := Split(wchan, NewWork(map[string]any{"from": 1, "to": 1_000_000, "table": "rows"}), RangeSplitter("from", "to", 100_000))
+		done()
+		<-j.IsDone()
+		close(pchan)
+
+		So(n, ShouldEqual, 10)
+		So(j.CompletedCount(), ShouldEqual, 10)
+		So(ranges, ShouldHaveLength, 10)
+
+		sort.Slice(ranges, func(a, b int) bool { return ranges[a][0] < ranges[b][0] })
+		So(ranges[0], ShouldEqual, [2]int64{1, 100_000})
+		So(ranges[9], ShouldEqual, [2]int64{900_001, 1_000_000})
+		for i := 1; i < len(ranges); i++ {
+			So(ranges[i][0], ShouldEqual, ranges[i-1][1]+1)
+		}
+	})
+
+	Convey("When a RangeSplitter splits an uneven range, the last chunk is short, and the other keys are kept.", t, func() {
+		subs := RangeSplitter("from", "to", 4)(NewWork(map[string]any{"from": 0, "to": 9, "table": "rows"}))
+		So(subs, ShouldHaveLength, 3)
+		So(subs[2].GetInt64("from"), ShouldEqual, 8)
+		So(subs[2].GetInt64("to"), ShouldEqual, 9)
+		So(subs[2].GetString("table"), ShouldEqual, "rows")
+
+		So(RangeSplitter("from", "to", 4)(NewWork(map[string]any{"from": 5, "to": 4})), ShouldBeEmpty)
+		So(func() { RangeSplitter("from", "to", 0) }, ShouldPanicWith, "racket: RangeSplitter called with a chunkSize of 0, less than 1")
+	})
+}
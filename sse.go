@@ -0,0 +1,45 @@
+package racket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// ProgressSSEHandler returns an http.Handler that streams each Progress from progressChan to the client as a
+// Server-Sent Event, with the JSON-encoded Progress as the "data:" field, flushing after each event.
+// The handler returns when the client disconnects or progressChan is closed. As there is only one progressChan,
+// concurrent clients will each receive a share of the Progress, rather than all of it.
+func ProgressSSEHandler(progressChan <-chan Progress) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				// client went away
+				return
+			case p, ok := <-progressChan:
+				if !ok {
+					return
+				}
+				b, err := json.Marshal(p)
+				if err != nil {
+					b, _ = json.Marshal(PErrorf("unable to marshal %s: %w", p.Type, err))
+				}
+				fmt.Fprintf(w, "data: %s\n\n", b)
+				flusher.Flush()
+			}
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package racket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ProgressSSEHandler(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	Convey("When a ProgressSSEHandler is streaming, events are written until the client disconnects", t, func() {
+		pchan := make(chan Progress)
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest(http.MethodGet, "/progress", nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+
+		returned := make(chan struct{})
+		go func() {
+			defer close(returned)
+			ProgressSSEHandler(pchan).ServeHTTP(rec, req)
+		}()
+
+		pchan <- PMessagef("Hello")
+		pchan <- PUpdate(42)
+		cancel()
+		<-returned
+
+		So(rec.Code, ShouldEqual, http.StatusOK)
+		So(rec.Header().Get("Content-Type"), ShouldEqual, "text/event-stream")
+		So(rec.Body.String(), ShouldEqual,
+			"data: {\"type\":\"ProgressMessage\",\"data\":\"Hello\"}\n\n"+
+				"data: {\"type\":\"ProgressUpdate\",\"data\":42}\n\n")
+	})
+
+	Convey("When a ProgressSSEHandler's channel is closed, the handler returns", t, func() {
+		pchan := make(chan Progress)
+		rec := httptest.NewRecorder()
+
+		returned := make(chan struct{})
+		go func() {
+			defer close(returned)
+			ProgressSSEHandler(pchan).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/progress", nil))
+		}()
+
+		pchan <- PErrorf("an ERROR")
+		close(pchan)
+		<-returned
+
+		So(rec.Body.String(), ShouldEqual, "data: {\"type\":\"ProgressError\",\"data\":\"an ERROR\"}\n\n")
+	})
+}
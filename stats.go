@@ -0,0 +1,52 @@
+package racket
+
+import "sync"
+
+// ProgressStats counts the Progress a ProgressLogger handles (see LogStats), by type, whether it was logged or not,
+// e.g. for an end-of-run summary. The zero value is ready to use. It is safe for concurrent use, so the counts may be
+// read while the ProgressLogger runs.
+type ProgressStats struct {
+	lock   sync.Mutex
+	total  int64
+	counts map[ProgressType]int64
+}
+
+// add counts the Progress.
+func (s *ProgressStats) add(p Progress) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.counts == nil {
+		s.counts = make(map[ProgressType]int64)
+	}
+	s.total++
+	s.counts[p.Type]++
+}
+
+// Total returns how many Progress have been counted.
+func (s *ProgressStats) Total() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.total
+}
+
+// Count returns how many Progress of the type have been counted.
+func (s *ProgressStats) Count(t ProgressType) int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.counts[t]
+}
+
+// Errors returns how many errors have been counted: ProgressError and ProgressTimeout.
+func (s *ProgressStats) Errors() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.counts[ProgressError] + s.counts[ProgressTimeout]
+}
+
+// LogStats is a LoggerOption that makes ProgressLogger count each Progress it handles in the ProgressStats.
+func LogStats(stats *ProgressStats) LoggerOption {
+	return func(c *loggerConfig) {
+		c.stats = stats
+	}
+}
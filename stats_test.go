@@ -0,0 +1,38 @@
+package racket
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ProgressStats(t *testing.T) {
+
+	Convey("When a ProgressLogger counts into ProgressStats, the counts match what was sent, logged or not.", t, func() {
+		var stats ProgressStats
+		So(stats.Total(), ShouldEqual, 0)
+
+		pchan := make(chan Progress, 10)
+		for range 4 {
+			pchan <- PMessagef("Hello")
+		}
+		pchan <- PErrorf("Error!")
+		pchan <- PTimeoutf("Timeout!")
+		pchan <- PDebugf("dropped, but counted")
+		pchan <- PUpdate(1)
+		pchan <- PUpdate(1)
+		pchan <- PEstimate(2)
+		close(pchan)
+		ProgressLogger(log.New(io.Discard, "", 0), false, nil, pchan, nil, LogStats(&stats))
+
+		So(stats.Total(), ShouldEqual, 10)
+		So(stats.Errors(), ShouldEqual, 2)
+		So(stats.Count(ProgressMessage), ShouldEqual, 4)
+		So(stats.Count(ProgressDebug), ShouldEqual, 1)
+		So(stats.Count(ProgressUpdate), ShouldEqual, 2)
+		So(stats.Count(ProgressEstimate), ShouldEqual, 1)
+		So(stats.Count(ProgressWarning), ShouldEqual, 0)
+	})
+}
@@ -0,0 +1,42 @@
+package racket
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Submit starts the Job's Supervisor with maxWorkers, with the items queued in order on a work channel big enough
+// for all of them, and then Drains the Job, so the caller never touches the work channel, and items still in flight
+// get all of their retries (see WithRetry). If the Job stops early (e.g. StopAfterSuccesses), the rest of the items
+// are left in the channel, with nothing waiting to send them. As with Supervisor, the caller must consume the
+// returned Progress channel, wait on IsDone, and then close it (or use WithOwnedProgress, which closes it for them).
+// It panics if maxWorkers is less than 1.
+func Submit(j Job, maxWorkers int, items []Work) (progressChan chan Progress) {
+	if maxWorkers < 1 {
+		panic(fmt.Sprintf("racket: Submit called with a maxWorkers of %d, less than 1", maxWorkers))
+	}
+
+	workChan := make(chan Work, len(items))
+	for _, w := range items {
+		workChan <- w
+	}
+	progressChan, _ = j.Supervisor(maxWorkers, workChan)
+	j.Drain()
+	return progressChan
+}
+
+// RunJob is the one-call path: it makes a Job from the WorkerFunc and options, Submits the items to up to
+// maxWorkers, and returns the Job's Progress channel. The channel is owned by the Job (see WithOwnedProgress),
+// and is closed once all of the items are done, so ranging over it both consumes the Progress and waits for the
+// Job. It returns an error, and no channel, if workerFunc is nil or maxWorkers is less than 1.
+func RunJob(workerFunc WorkerFunc, maxWorkers int, items []Work, opts ...JobOption) (<-chan Progress, error) {
+	if workerFunc == nil {
+		return nil, errors.New("racket: RunJob called with a nil WorkerFunc")
+	}
+	if maxWorkers < 1 {
+		return nil, fmt.Errorf("racket: RunJob called with a maxWorkers of %d, less than 1", maxWorkers)
+	}
+
+	j := NewJob(workerFunc, append([]JobOption{WithOwnedProgress()}, opts...)...)
+	return Submit(j, maxWorkers, items), nil
+}
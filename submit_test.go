@@ -0,0 +1,99 @@
+package racket
+
+import (
+	"errors"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_Submit(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	its := 50
+	items := make([]Work, its)
+	for i := range items {
+		items[i] = NewWork(map[string]any{"n": i})
+	}
+
+	Convey("When items are Submitted to a Job, they are all done without touching the work channel.", t, func() {
+		var sum atomic.Int64
+		j := NewJob(func(id any, work Work, pchan chan<- Progress) {
+			sum.Add(work.GetInt64("n"))
+		})
+
+		pchan := Submit(j, 4, items)
+		go ProgressLogger(log.New(io.Discard, "", 0), false, nil, pchan, nil)
+		<-j.IsDone()
+		close(pchan)
+
+		So(j.CompletedCount(), ShouldEqual, its)
+		So(sum.Load(), ShouldEqual, its*(its-1)/2)
+	})
+
+	Convey("When items are Submitted to a retrying Job, every item gets its retries.", t, func() {
+		items := make([]Work, 4)
+		for i := range items {
+			items[i] = NewWork(map[string]any{"n": i})
+		}
+
+		var (
+			calls  atomic.Int64
+			failed sync.Map
+		)
+		j := NewJobRetry(func(id any, work Work) error {
+			calls.Add(1)
+			if _, again := failed.LoadOrStore(work.GetInt("n"), true); !again {
+				return errors.New("first attempt fails")
+			}
+			return nil
+		}, 3, nil)
+
+		pchan := Submit(j, 2, items)
+		go ProgressLogger(log.New(io.Discard, "", 0), false, nil, pchan, nil)
+		<-j.IsDone()
+		close(pchan)
+
+		So(calls.Load(), ShouldEqual, 8)
+		So(j.Completed(), ShouldEqual, 4)
+		So(j.Errors(), ShouldBeEmpty)
+	})
+
+	Convey("When a Job is Run, ranging over the Progress waits for all of the items.", t, func() {
+		var wCount atomic.Int64
+		pchan, err := RunJob(func(id any, work Work, pchan chan<- Progress) {
+			wCount.Add(1)
+			pchan <- Progress{Type: ProgressMessage, Data: "hello"}
+		}, 4, items)
+		So(err, ShouldBeNil)
+
+		var messages int
+		for p := range pchan {
+			if p.Type == ProgressMessage {
+				messages++
+			}
+		}
+		So(messages, ShouldEqual, its)
+		So(wCount.Load(), ShouldEqual, its)
+	})
+
+	Convey("When a Job that is Run stops early, nothing is left waiting to send the rest of the items.", t, func() {
+		pchan, err := RunJob(func(id any, work Work, pchan chan<- Progress) {}, 1, make([]Work, 10), StopAfterSuccesses(2))
+		So(err, ShouldBeNil)
+		for range pchan {
+		}
+	})
+
+	Convey("When a Job is Run with bad arguments, an error is returned.", t, func() {
+		_, err := RunJob(nil, 4, items)
+		So(err, ShouldBeError, "racket: RunJob called with a nil WorkerFunc")
+
+		_, err = RunJob(func(id any, work Work, pchan chan<- Progress) {}, 0, items)
+		So(err, ShouldBeError, "racket: RunJob called with a maxWorkers of 0, less than 1")
+	})
+}
@@ -0,0 +1,52 @@
+package racket
+
+import (
+	"context"
+)
+
+// TraceKey is the Work key holding a serialized trace context (e.g. a W3C traceparent) that links the Work to
+// the trace of the request that originated it, see WithTracing.
+const TraceKey = "_traceparent"
+
+// TraceSpanName is the name of the span started for each unit of Work, see WithTracing.
+const TraceSpanName = "racket.Work"
+
+// TracePropagator restores a remote span context, from a serialized trace context, into a context.Context.
+// An OpenTelemetry propagation.TextMapPropagator is easily adapted, without racket depending on OpenTelemetry.
+type TracePropagator interface {
+	Extract(ctx context.Context, traceparent string) context.Context
+}
+
+// Tracer starts a span, as a child of any span in the context, returning a context holding the new span.
+// An OpenTelemetry trace.Tracer is easily adapted, without racket depending on OpenTelemetry.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer.
+type Span interface {
+	// RecordError records the error the Work failed with.
+	RecordError(err error)
+	// End ends the span.
+	End()
+}
+
+// trace returns a context with a span started for the Work, as a child of the remote span context in the Work's
+// TraceKey if there is one, and a func to end the span with the Work's final error.
+func (j *defaultJob) trace(ctx context.Context, w Work) (context.Context, func(error)) {
+	if j.tracer == nil {
+		return ctx, func(error) {}
+	}
+
+	if tp, ok := w.config[TraceKey].(string); ok && tp != "" && j.propagator != nil {
+		ctx = j.propagator.Extract(ctx, tp)
+	}
+	ctx, span := j.tracer.Start(ctx, TraceSpanName)
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+}
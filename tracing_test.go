@@ -0,0 +1,136 @@
+package racket
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fortytw2/leaktest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeSpanKey is the context key for the current fakeSpan, or a remote parent span ID.
+type fakeSpanKey struct{}
+
+// fakeSpan is a Span that records its parent, errors, and ending.
+type fakeSpan struct {
+	lock   sync.Mutex
+	name   string
+	parent string
+	errs   []error
+	ended  bool
+}
+
+func (s *fakeSpan) RecordError(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.errs = append(s.errs, err)
+}
+
+func (s *fakeSpan) End() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ended = true
+}
+
+// fakeTracer is a Tracer that keeps the spans it started.
+type fakeTracer struct {
+	lock  sync.Mutex
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &fakeSpan{name: name}
+	if parent, ok := ctx.Value(fakeSpanKey{}).(string); ok {
+		span.parent = parent
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, fakeSpanKey{}, span), span
+}
+
+// fakePropagator is a TracePropagator of "version-traceid-spanid-flags" traceparents, restoring the span ID.
+type fakePropagator struct{}
+
+func (fakePropagator) Extract(ctx context.Context, traceparent string) context.Context {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 {
+		return ctx
+	}
+	return context.WithValue(ctx, fakeSpanKey{}, parts[2])
+}
+
+func Test_JobTracing(t *testing.T) {
+	defer leaktest.Check(t)()
+
+	disco := log.New(io.Discard, "", 0)
+
+	Convey("When a Job has tracing, each Work gets a span linked to its remote trace context.", t, func(c C) {
+		tracer := &fakeTracer{}
+		var (
+			lock   sync.Mutex
+			inWork = make(map[string]*fakeSpan)
+		)
+
+		j := NewJobContext(func(ctx context.Context, id any, work Work, pchan chan<- Progress) {
+			lock.Lock()
+			defer lock.Unlock()
+			span, _ := ctx.Value(fakeSpanKey{}).(*fakeSpan)
+			inWork[work.GetString("name")] = span
+		}, WithTracing(tracer, fakePropagator{}))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(2, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(map[string]any{
+			"name":   "linked",
+			TraceKey: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		})
+		wchan <- NewWork(map[string]any{"name": "orphan"})
+		done()
+		<-j.IsDone()
+
+		lock.Lock()
+		defer lock.Unlock()
+		c.So(tracer.spans, ShouldHaveLength, 2)
+
+		linked := inWork["linked"]
+		c.So(linked, ShouldNotBeNil)
+		c.So(linked.name, ShouldEqual, TraceSpanName)
+		c.So(linked.parent, ShouldEqual, "00f067aa0ba902b7")
+		c.So(linked.ended, ShouldBeTrue)
+
+		orphan := inWork["orphan"]
+		c.So(orphan, ShouldNotBeNil)
+		c.So(orphan.parent, ShouldBeEmpty)
+		c.So(orphan.ended, ShouldBeTrue)
+	})
+
+	Convey("When traced Work fails, the error is recorded on the span.", t, func(c C) {
+		tracer := &fakeTracer{}
+		boom := errors.New("boom")
+
+		j := NewJobErr(func(id any, work Work) error {
+			return boom
+		}, WithTracing(tracer, nil))
+		wchan := make(chan Work)
+		pchan, done := j.Supervisor(1, wchan)
+		defer close(pchan)
+		go ProgressLogger(disco, false, nil, pchan, nil)
+
+		wchan <- NewWork(nil)
+		done()
+		<-j.IsDone()
+
+		c.So(tracer.spans, ShouldHaveLength, 1)
+		c.So(tracer.spans[0].errs, ShouldResemble, []error{boom})
+		c.So(tracer.spans[0].ended, ShouldBeTrue)
+	})
+}
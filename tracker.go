@@ -0,0 +1,310 @@
+package racket
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TrackerOption is a functional option to tune the behavior of a ProgressTracker.
+type TrackerOption func(*ProgressTracker)
+
+// ProgressTracker consumes ProgressUpdate, ProgressEstimate, and ProgressRemaining (e.g. from a ProgressLogger
+// barChan) to maintain a running total and an estimate of how much work is to be performed. It is goro-safe.
+type ProgressTracker struct {
+	lock     sync.Mutex
+	total    int64
+	estimate int64
+	started  time.Time
+
+	clampZero     bool
+	clampEstimate bool
+}
+
+// ClampAtZero prevents the tracked total from going negative, if decrements outpace increments.
+// The raw deltas still flow as-is; only the tracked total is clamped.
+func ClampAtZero() TrackerOption {
+	return func(t *ProgressTracker) {
+		t.clampZero = true
+	}
+}
+
+// ClampAtEstimate prevents the tracked total from exceeding a non-zero estimate.
+// The raw deltas still flow as-is; only the tracked total is clamped.
+func ClampAtEstimate() TrackerOption {
+	return func(t *ProgressTracker) {
+		t.clampEstimate = true
+	}
+}
+
+// NewProgressTracker returns a ProgressTracker, tuned by any supplied TrackerOptions.
+func NewProgressTracker(opts ...TrackerOption) *ProgressTracker {
+	t := &ProgressTracker{}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Track accounts for the Progress if it is a ProgressUpdate, ProgressEstimate, or ProgressRemaining, and ignores
+// it otherwise. A ProgressRemaining re-estimates to the current total plus the remaining count.
+func (t *ProgressTracker) Track(p Progress) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switch p.Type {
+	case ProgressUpdate:
+		if t.started.IsZero() {
+			t.started = time.Now()
+		}
+		t.total += p.Data.(int64)
+	case ProgressEstimate:
+		t.estimate = p.Data.(int64)
+	case ProgressRemaining:
+		// What's done plus what's left is the total
+		t.estimate = t.total + p.Data.(int64)
+	default:
+		return
+	}
+	t.clamp()
+}
+
+// Consume Tracks every Progress from progressChan, until it is closed.
+func (t *ProgressTracker) Consume(progressChan <-chan Progress) {
+	for p := range progressChan {
+		t.Track(p)
+	}
+}
+
+// Total returns the tracked total.
+func (t *ProgressTracker) Total() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.total
+}
+
+// Estimate returns the most recent estimate, whether reported directly or derived from a ProgressRemaining.
+func (t *ProgressTracker) Estimate() int64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return t.estimate
+}
+
+// Percent returns the tracked total as a percentage (0-100) of the estimate, or 0 if there is no estimate.
+func (t *ProgressTracker) Percent() float64 {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return percent(t.total, t.estimate)
+}
+
+// ETA returns how long until the tracked total reaches the estimate, at the average rate since the first
+// ProgressUpdate, or 0 if it can't be known yet (or is already reached).
+func (t *ProgressTracker) ETA() time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.started.IsZero() || t.total <= 0 || t.total >= t.estimate {
+		return 0
+	}
+	elapsed := time.Since(t.started)
+	return time.Duration(float64(elapsed) / float64(t.total) * float64(t.estimate-t.total))
+}
+
+// clamp applies any clamping options to the total. Must be called with the lock held.
+func (t *ProgressTracker) clamp() {
+	if t.clampEstimate && t.estimate > 0 && t.total > t.estimate {
+		t.total = t.estimate
+	}
+	if t.clampZero && t.total < 0 {
+		t.total = 0
+	}
+}
+
+// percent returns total as a percentage (0-100) of estimate, or 0 if estimate isn't positive.
+func percent(total, estimate int64) float64 {
+	if estimate <= 0 {
+		return 0
+	}
+	return min(max(float64(total)/float64(estimate)*100, 0), 100)
+}
+
+// MultiTracker aggregates the progress of multiple sources (e.g. the barChans of several Jobs) into one
+// combined view, using a ProgressTracker per source. It is goro-safe.
+type MultiTracker struct {
+	lock     sync.Mutex
+	trackers map[string]*ProgressTracker
+	opts     []TrackerOption
+}
+
+// NewMultiTracker returns a MultiTracker, whose per-source ProgressTrackers are tuned by any supplied TrackerOptions.
+func NewMultiTracker(opts ...TrackerOption) *MultiTracker {
+	return &MultiTracker{
+		trackers: make(map[string]*ProgressTracker),
+		opts:     opts,
+	}
+}
+
+// Tracker returns the ProgressTracker for the source, creating it if needed.
+func (m *MultiTracker) Tracker(source string) *ProgressTracker {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	t, ok := m.trackers[source]
+	if !ok {
+		t = NewProgressTracker(m.opts...)
+		m.trackers[source] = t
+	}
+	return t
+}
+
+// Track accounts for the Progress against the source.
+func (m *MultiTracker) Track(source string, p Progress) {
+	m.Tracker(source).Track(p)
+}
+
+// Consume Tracks every Progress from progressChan against the source, until it is closed.
+func (m *MultiTracker) Consume(source string, progressChan <-chan Progress) {
+	m.Tracker(source).Consume(progressChan)
+}
+
+// Percent returns the combined total as a percentage (0-100) of the combined estimate, so each source
+// is weighted by its estimate.
+func (m *MultiTracker) Percent() float64 {
+	var total, estimate int64
+	for _, t := range m.snapshot() {
+		total += t.Total()
+		estimate += t.Estimate()
+	}
+	return percent(total, estimate)
+}
+
+// ETA returns the longest ETA of the sources, as they are assumed to be progressing concurrently.
+func (m *MultiTracker) ETA() time.Duration {
+	var eta time.Duration
+	for _, t := range m.snapshot() {
+		eta = max(eta, t.ETA())
+	}
+	return eta
+}
+
+// Percents returns the Percent of each source.
+func (m *MultiTracker) Percents() map[string]float64 {
+	percents := make(map[string]float64)
+	for source, t := range m.snapshot() {
+		percents[source] = t.Percent()
+	}
+	return percents
+}
+
+// snapshot returns a copy of the source to ProgressTracker map.
+func (m *MultiTracker) snapshot() map[string]*ProgressTracker {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	trackers := make(map[string]*ProgressTracker, len(m.trackers))
+	for source, t := range m.trackers {
+		trackers[source] = t
+	}
+	return trackers
+}
+
+// etaMinSamples is how many rate samples (intervals between ProgressUpdates) an ETAEstimator needs before it
+// reports a Rate or Remaining.
+const etaMinSamples = 3
+
+// ETAEstimator consumes ProgressUpdate, ProgressEstimate, and ProgressRemaining, like a ProgressTracker, but
+// estimates the Rate of progress as an exponentially weighted moving average of the rate between updates, so
+// a short stall (or burst) only nudges it, and how long Remains until the estimate is reached at that Rate. The
+// estimate may change mid-run. It is goro-safe.
+type ETAEstimator struct {
+	lock     sync.Mutex
+	alpha    float64
+	total    int64
+	estimate int64
+	pending  int64 // counted since the last sample
+	last     time.Time
+	rate     float64 // units per second
+	samples  int
+}
+
+// NewETAEstimator returns an ETAEstimator, whose moving average weighs each new rate sample by alpha, and the
+// average so far by 1-alpha: a higher alpha reacts faster, a lower one is smoother (e.g. 0.1). It panics if alpha
+// isn't greater than 0 and at most 1.
+func NewETAEstimator(alpha float64) *ETAEstimator {
+	if alpha <= 0 || alpha > 1 {
+		panic(fmt.Sprintf("racket: NewETAEstimator called with an alpha of %g, not in (0, 1]", alpha))
+	}
+	return &ETAEstimator{alpha: alpha}
+}
+
+// Track accounts for the Progress as of now, see TrackAt.
+func (e *ETAEstimator) Track(p Progress) {
+	e.TrackAt(p, time.Now())
+}
+
+// TrackAt accounts for the Progress as of at, e.g. when replaying recorded Progress, if it is a ProgressUpdate,
+// ProgressEstimate, or ProgressRemaining, and ignores it otherwise. Each ProgressUpdate after the first samples
+// the rate since the one before.
+func (e *ETAEstimator) TrackAt(p Progress, at time.Time) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	switch p.Type {
+	case ProgressUpdate:
+		n := p.Data.(int64)
+		e.total += n
+		if e.last.IsZero() {
+			e.last = at
+			return
+		}
+		e.pending += n
+		elapsed := at.Sub(e.last).Seconds()
+		if elapsed <= 0 {
+			return // sample it with the next
+		}
+		sample := float64(e.pending) / elapsed
+		if e.samples == 0 {
+			e.rate = sample
+		} else {
+			e.rate = e.alpha*sample + (1-e.alpha)*e.rate
+		}
+		e.samples++
+		e.pending = 0
+		e.last = at
+	case ProgressEstimate:
+		e.estimate = p.Data.(int64)
+	case ProgressRemaining:
+		// What's done plus what's left is the total
+		e.estimate = e.total + p.Data.(int64)
+	}
+}
+
+// Consume Tracks every Progress from progressChan, until it is closed.
+func (e *ETAEstimator) Consume(progressChan <-chan Progress) {
+	for p := range progressChan {
+		e.Track(p)
+	}
+}
+
+// Rate returns the moving average rate of progress, in units per second, or 0 if there aren't enough samples yet.
+func (e *ETAEstimator) Rate() float64 {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.samples < etaMinSamples {
+		return 0
+	}
+	return e.rate
+}
+
+// Remaining returns how long until the total reaches the estimate at the Rate, or 0 if there aren't enough samples
+// yet, the Rate isn't positive, or the estimate is already reached.
+func (e *ETAEstimator) Remaining() time.Duration {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.samples < etaMinSamples || e.rate <= 0 || e.total >= e.estimate {
+		return 0
+	}
+	return time.Duration(float64(e.estimate-e.total) / e.rate * float64(time.Second))
+}
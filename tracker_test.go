@@ -0,0 +1,209 @@
+package racket
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_ProgressTracker(t *testing.T) {
+
+	Convey("When a ProgressTracker is fed Progress, it tracks the total and estimate", t, func() {
+		pt := NewProgressTracker()
+		pt.Track(PEstimate(10))
+		pt.Track(PUpdate(3))
+		pt.Track(PUpdate(-1))
+		pt.Track(PMessagef("ignored"))
+
+		So(pt.Estimate(), ShouldEqual, 10)
+		So(pt.Total(), ShouldEqual, 2)
+
+		Convey("... and without clamping, more decrements than increments go negative", func() {
+			pt.Track(PUpdate(-5))
+			So(pt.Total(), ShouldEqual, -3)
+		})
+	})
+
+	Convey("When a ProgressTracker clamps at zero, more decrements than increments stay at zero", t, func() {
+		pt := NewProgressTracker(ClampAtZero())
+		pt.Track(PUpdate(1))
+		for range 5 {
+			pt.Track(PUpdate(-1))
+			So(pt.Total(), ShouldBeGreaterThanOrEqualTo, 0)
+		}
+		So(pt.Total(), ShouldEqual, 0)
+
+		pt.Track(PUpdate(2))
+		So(pt.Total(), ShouldEqual, 2)
+	})
+
+	Convey("When a ProgressTracker clamps at the estimate, the total does not exceed it", t, func() {
+		pt := NewProgressTracker(ClampAtEstimate())
+		pt.Track(PUpdate(5)) // no estimate yet, so no clamping
+		So(pt.Total(), ShouldEqual, 5)
+
+		pt.Track(PEstimate(4))
+		So(pt.Total(), ShouldEqual, 4)
+
+		pt.Track(PUpdate(10))
+		So(pt.Total(), ShouldEqual, 4)
+	})
+
+	Convey("When a ProgressTracker is fed ProgressRemaining, the estimate is the total plus the remaining", t, func() {
+		pt := NewProgressTracker()
+		pt.Track(PRemaining(10))
+		So(pt.Estimate(), ShouldEqual, 10)
+
+		pt.Track(PUpdate(4))
+		pt.Track(PRemaining(6))
+		So(pt.Total(), ShouldEqual, 4)
+		So(pt.Estimate(), ShouldEqual, 10)
+
+		// more work was discovered
+		pt.Track(PUpdate(1))
+		pt.Track(PRemaining(10))
+		So(pt.Estimate(), ShouldEqual, 15)
+	})
+
+	Convey("When a ProgressTracker consumes a channel, it tracks until it is closed", t, func() {
+		pchan := make(chan Progress)
+		pt := NewProgressTracker(ClampAtZero())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			pt.Consume(pchan)
+		}()
+
+		pchan <- PEstimate(2)
+		pchan <- PUpdate(-1)
+		pchan <- PUpdate(1)
+		close(pchan)
+		<-done
+
+		So(pt.Estimate(), ShouldEqual, 2)
+		So(pt.Total(), ShouldEqual, 1)
+	})
+}
+
+func Test_ProgressTrackerPercentETA(t *testing.T) {
+
+	Convey("When a ProgressTracker has no estimate, Percent and ETA are zero", t, func() {
+		pt := NewProgressTracker()
+		So(pt.Percent(), ShouldEqual, 0)
+		So(pt.ETA(), ShouldEqual, 0)
+
+		pt.Track(PUpdate(5))
+		So(pt.Percent(), ShouldEqual, 0)
+		So(pt.ETA(), ShouldEqual, 0)
+	})
+
+	Convey("When a ProgressTracker is partway, Percent and ETA reflect it", t, func() {
+		pt := NewProgressTracker()
+		pt.Track(PEstimate(4))
+		pt.Track(PUpdate(1))
+		time.Sleep(50 * time.Millisecond)
+		pt.Track(PUpdate(1))
+
+		So(pt.Percent(), ShouldEqual, 50)
+		So(pt.ETA(), ShouldBeBetween, 25*time.Millisecond, time.Second)
+
+		pt.Track(PUpdate(2))
+		So(pt.Percent(), ShouldEqual, 100)
+		So(pt.ETA(), ShouldEqual, 0)
+	})
+
+	Convey("When a ProgressTracker has an estimate of 100, and ten updates of 10, it is at 100%", t, func() {
+		pt := NewProgressTracker()
+		pt.Track(PEstimate(100))
+		for range 10 {
+			pt.Track(PUpdate(10))
+		}
+		So(pt.Percent(), ShouldEqual, 100)
+
+		Convey("... but with an estimate of zero, it is at 0%, rather than dividing by zero", func() {
+			pt.Track(PEstimate(0))
+			So(pt.Percent(), ShouldEqual, 0)
+		})
+	})
+}
+
+func Test_MultiTracker(t *testing.T) {
+
+	Convey("When a MultiTracker is fed two sources with different estimates, the combined percent is weighted", t, func() {
+		mt := NewMultiTracker()
+		So(mt.Percent(), ShouldEqual, 0)
+
+		a := make(chan Progress)
+		consumed := make(chan struct{})
+		go func() {
+			defer close(consumed)
+			mt.Consume("a", a)
+		}()
+		a <- PEstimate(100)
+		a <- PUpdate(50)
+		close(a)
+		<-consumed
+
+		mt.Track("b", PEstimate(300))
+
+		So(mt.Percent(), ShouldEqual, 12.5)
+		So(mt.Percents(), ShouldResemble, map[string]float64{"a": 50, "b": 0})
+		So(mt.Tracker("a").Total(), ShouldEqual, 50)
+
+		mt.Track("b", PUpdate(300))
+		So(mt.Percent(), ShouldEqual, 87.5)
+		So(mt.ETA(), ShouldBeGreaterThan, 0) // a isn't done
+	})
+}
+
+func Test_ETAEstimator(t *testing.T) {
+
+	// steady Tracks n ProgressUpdates of 10, every 100ms (100/s), from at, returning when the last was.
+	steady := func(e *ETAEstimator, at time.Time, n int) time.Time {
+		for range n {
+			at = at.Add(100 * time.Millisecond)
+			e.TrackAt(PUpdate(10), at)
+		}
+		return at
+	}
+
+	Convey("When an ETAEstimator doesn't have enough samples yet, Rate and Remaining are zero", t, func() {
+		e := NewETAEstimator(0.2)
+		e.Track(PEstimate(1000))
+		steady(e, time.Now(), etaMinSamples)
+		So(e.Rate(), ShouldEqual, 0)
+		So(e.Remaining(), ShouldEqual, 0)
+	})
+
+	Convey("When an ETAEstimator is fed a steady rate, Remaining converges on the expected value", t, func() {
+		e := NewETAEstimator(0.2)
+		e.Track(PEstimate(1000))
+		at := steady(e, time.Now(), 50)
+
+		So(e.Rate(), ShouldAlmostEqual, 100, 1)
+		So(e.Remaining(), ShouldAlmostEqual, 5*time.Second, 50*time.Millisecond) // 500 left, at 100/s
+
+		Convey("... and a short stall only nudges it, before it converges again", func() {
+			at = steady(e, at.Add(time.Second), 1)
+			So(e.Rate(), ShouldBeBetween, 50, 100)
+			So(e.Remaining(), ShouldBeBetween, 5*time.Second, 10*time.Second)
+
+			steady(e, at, 30)
+			So(e.Remaining(), ShouldAlmostEqual, 1900*time.Millisecond, 50*time.Millisecond) // 190 left
+		})
+
+		Convey("... and re-estimated mid-run, Remaining follows the new estimate", func() {
+			e.Track(PEstimate(2000))
+			So(e.Remaining(), ShouldAlmostEqual, 15*time.Second, 150*time.Millisecond)
+
+			e.Track(PRemaining(0))
+			So(e.Remaining(), ShouldEqual, 0)
+		})
+	})
+
+	Convey("When an ETAEstimator is made with a bad alpha, it panics", t, func() {
+		So(func() { NewETAEstimator(0) }, ShouldPanicWith, "racket: NewETAEstimator called with an alpha of 0, not in (0, 1]")
+		So(func() { NewETAEstimator(1.5) }, ShouldPanic)
+	})
+}
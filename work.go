@@ -1,9 +1,65 @@
 package racket
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/spf13/cast"
 )
 
+// ErrMissingKey is returned (wrapped) by the error-returning Work getters when the key does not exist.
+var ErrMissingKey = errors.New("racket: work key does not exist")
+
+// workDebug is the logger for missing Work key warnings, or nil if debugging is off.
+var workDebug atomic.Pointer[log.Logger]
+
+// SetWorkDebug turns on a debug mode where the Work getters log a warning to the logger when asked for a
+// key that doesn't exist, so silent zero-value returns don't mask typos in keys. Passing nil turns it
+// back off, which is the default, and what you want in production.
+func SetWorkDebug(logger *log.Logger) {
+	workDebug.Store(logger)
+}
+
+// sensitiveKeys is the set of Work keys whose values are masked by String and MarshalJSON.
+var (
+	sensitiveKeys     = make(map[string]struct{})
+	sensitiveKeysLock sync.RWMutex
+)
+
+// sensitiveMask is what the values of sensitive keys are rendered as.
+const sensitiveMask = "***"
+
+// MarkSensitive registers the keys as sensitive for all Work, so their values are rendered as "***" by String
+// and MarshalJSON, making Work safe to log. The values are still readable via the getters, and kept by
+// MarshalUnmasked, for persisting Work.
+func MarkSensitive(keys ...string) {
+	sensitiveKeysLock.Lock()
+	defer sensitiveKeysLock.Unlock()
+
+	for _, k := range keys {
+		sensitiveKeys[k] = struct{}{}
+	}
+}
+
+// unmarkSensitive undoes MarkSensitive for the keys, e.g. so a test doesn't leave them marked for the rest.
+func unmarkSensitive(keys ...string) {
+	sensitiveKeysLock.Lock()
+	defer sensitiveKeysLock.Unlock()
+
+	for _, k := range keys {
+		delete(sensitiveKeys, k)
+	}
+}
+
 // Work is a representation of specification to pass to a Worker doing a Job.
 type Work struct {
 	config map[string]any
@@ -16,22 +72,384 @@ func NewWork(config map[string]any) Work {
 	}
 }
 
+// WorkFromValues takes url.Values (e.g. a parsed query string or form) and returns a specified unit of Work.
+// Parameters with a single value are flattened to a string, while parameters with multiple values are kept
+// as a []string. Either way GetString and GetStringSlice behave naturally: GetString of a multi-valued parameter
+// returns the empty string, and GetStringSlice of a single-valued parameter returns a one-element slice.
+func WorkFromValues(v url.Values) Work {
+	config := make(map[string]any, len(v))
+	for key, values := range v {
+		switch len(values) {
+		case 0:
+			config[key] = ""
+		case 1:
+			config[key] = values[0]
+		default:
+			config[key] = values
+		}
+	}
+	return NewWork(config)
+}
+
+// Clone returns a new Work with a shallow copy of the Work's map, so the clone can be Set or Deleted from without
+// affecting the original, e.g. to stamp out items from a template that is concurrently being read by a worker.
+// The values themselves are not copied, so mutable values (slices, maps, pointers) are still shared.
+func (w Work) Clone() Work {
+	return w.Merge(Work{})
+}
+
+// NewWorkFromStruct takes a struct, or a pointer to one, and returns a specified unit of Work with the values of its
+// exported fields, keyed by field name, or by the name in a `racket:"name"` tag. Fields tagged `racket:"-"`, and
+// unexported fields, are skipped. Anything else returns an error.
+func NewWorkFromStruct(v any) (Work, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return Work{}, fmt.Errorf("racket: NewWorkFromStruct called with a nil %T", v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return Work{}, fmt.Errorf("racket: NewWorkFromStruct called with a %T, not a struct", v)
+	}
+
+	rt := rv.Type()
+	config := make(map[string]any, rt.NumField())
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		key := field.Name
+		if tag, ok := field.Tag.Lookup("racket"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				key = tag
+			}
+		}
+		config[key] = rv.Field(i).Interface()
+	}
+	return NewWork(config), nil
+}
+
+// Merge returns a new Work containing all of the keys from the Work, overlaid with the keys from other, which win
+// on conflict. Neither Work is modified, and either may be from a nil map.
+func (w Work) Merge(other Work) Work {
+	config := make(map[string]any, len(w.config)+len(other.config))
+	for k, v := range w.config {
+		config[k] = v
+	}
+	for k, v := range other.config {
+		config[k] = v
+	}
+	return NewWork(config)
+}
+
+// lookup returns the value associated with the key, or nil, warning if the key is missing and SetWorkDebug is on.
+func (w *Work) lookup(key string) any {
+	v, ok := w.config[key]
+	if !ok {
+		if logger := workDebug.Load(); logger != nil {
+			logger.Printf("[RACKET] WARNING: work key %q does not exist\n", key)
+		}
+	}
+	return v
+}
+
+// Has returns true if the key exists, even if its value is nil, so a zero value from a getter can be told apart
+// from a missing key.
+func (w *Work) Has(key string) bool {
+	_, ok := w.config[key]
+	return ok
+}
+
+// Set associates the value with the key, allocating the underlying map if the Work was made from nil.
+// Work is passed to Workers by value, sharing the underlying map, so Set (and Delete) must only be used
+// while building the Work, before it is placed on the work channel.
+func (w *Work) Set(key string, value any) {
+	if w.config == nil {
+		w.config = make(map[string]any)
+	}
+	w.config[key] = value
+}
+
+// Delete removes the key, if it exists. Like Set, it must only be used before the Work is placed on the work channel.
+func (w *Work) Delete(key string) {
+	delete(w.config, key)
+}
+
+// missingKeysError is an error naming the missing keys, that wraps ErrMissingKey.
+type missingKeysError []string
+
+// Error names the missing keys.
+func (m missingKeysError) Error() string {
+	return fmt.Sprintf("racket: missing required work keys: [%s]", strings.Join(m, ", "))
+}
+
+// Unwrap returns ErrMissingKey.
+func (m missingKeysError) Unwrap() error {
+	return ErrMissingKey
+}
+
+// Require returns an error (wrapping ErrMissingKey) naming all of the keys the Work doesn't have, or nil if it has
+// them all, so a worker can check its parameters up front, and send the error as a ProgressError.
+func (w *Work) Require(keys ...string) error {
+	var missing missingKeysError
+	for _, k := range keys {
+		if !w.Has(k) {
+			missing = append(missing, k)
+		}
+	}
+	if len(missing) > 0 {
+		return missing
+	}
+	return nil
+}
+
+// Keys returns the keys of the Work, sorted, or an empty slice if there are none.
+func (w *Work) Keys() []string {
+	keys := make([]string, 0, len(w.config))
+	for k := range w.config {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Len returns the number of keys in the Work.
+func (w *Work) Len() int {
+	return len(w.config)
+}
+
 // Get returns the value associated with the key, or nil.
 func (w *Work) Get(key string) any {
-	return w.config[key]
+	return w.lookup(key)
 }
 
 // GetString returns the string-ified value associated with the key.
 func (w *Work) GetString(key string) string {
-	return cast.ToString(w.config[key])
+	return cast.ToString(w.lookup(key))
+}
+
+// GetStringSlice returns the []string-ified value associated with the key.
+// A lone string value is returned as a one-element slice, rather than split on whitespace.
+func (w *Work) GetStringSlice(key string) []string {
+	v := w.lookup(key)
+	if s, ok := v.(string); ok {
+		return []string{s}
+	}
+	return cast.ToStringSlice(v)
 }
 
 // GetBool returns the bool-ified value associated with the key.
 func (w *Work) GetBool(key string) bool {
-	return cast.ToBool(w.config[key])
+	return cast.ToBool(w.lookup(key))
 }
 
 // GetInt returns the int-ifiied value associated with the key.
 func (w *Work) GetInt(key string) int {
-	return cast.ToInt(w.config[key])
+	return cast.ToInt(w.lookup(key))
+}
+
+// GetInt64 returns the int64-ified value associated with the key.
+func (w *Work) GetInt64(key string) int64 {
+	return cast.ToInt64(w.lookup(key))
+}
+
+// GetFloat64 returns the float64-ified value associated with the key.
+func (w *Work) GetFloat64(key string) float64 {
+	return cast.ToFloat64(w.lookup(key))
+}
+
+// GetDuration returns the time.Duration-ified value associated with the key: a time.Duration, an integer number of
+// nanoseconds, or a parseable string such as "30s".
+func (w *Work) GetDuration(key string) time.Duration {
+	return cast.ToDuration(w.lookup(key))
+}
+
+// GetTime returns the time.Time-ified value associated with the key: a time.Time, an RFC3339 (or similar) string,
+// or an integer Unix epoch in seconds. A missing key or uncastable value returns the zero time.Time.
+func (w *Work) GetTime(key string) time.Time {
+	return cast.ToTime(w.lookup(key))
+}
+
+// GetAs returns the value associated with the key as a T, by type assertion rather than cast, for values the
+// other getters can't handle, such as structs: cfg, ok := GetAs[Config](work, "cfg"). A missing key, or a value
+// that isn't a T, returns the zero T and false.
+func GetAs[T any](w Work, key string) (T, bool) {
+	t, ok := w.lookup(key).(T)
+	return t, ok
+}
+
+// IntE returns the int-ified value associated with the key, or an error if the value can't be cast to an int.
+// An absent key returns an error wrapping ErrMissingKey, distinguishing it from a present value that isn't a number.
+// It is GetIntStrict, by another name.
+func (w *Work) IntE(key string) (int, error) {
+	return w.GetIntStrict(key)
+}
+
+// strict returns the value associated with the key converted by the cast, or an error wrapping ErrMissingKey
+// if the key is absent, or describing the stored type if it can't be converted to the kind.
+func strict[T any](w *Work, key, kind string, cast func(any) (T, error)) (T, error) {
+	var zero T
+	v, ok := w.config[key]
+	if !ok {
+		return zero, fmt.Errorf("%w: %q", ErrMissingKey, key)
+	}
+	t, err := cast(v)
+	if err != nil {
+		return zero, fmt.Errorf("racket: work key %q: expected %s-convertible, got %T", key, kind, v)
+	}
+	return t, nil
+}
+
+// GetStringStrict is GetString, but returns an error if the key is absent (wrapping ErrMissingKey), or if the
+// value can't be converted, describing the type actually stored, rather than silently returning the zero value.
+func (w *Work) GetStringStrict(key string) (string, error) {
+	return strict(w, key, "string", cast.ToStringE)
+}
+
+// GetStringSliceStrict is GetStringSlice, but returns an error as GetStringStrict does.
+func (w *Work) GetStringSliceStrict(key string) ([]string, error) {
+	return strict(w, key, "[]string", func(v any) ([]string, error) {
+		if s, ok := v.(string); ok {
+			return []string{s}, nil
+		}
+		return cast.ToStringSliceE(v)
+	})
+}
+
+// GetBoolStrict is GetBool, but returns an error as GetStringStrict does.
+func (w *Work) GetBoolStrict(key string) (bool, error) {
+	return strict(w, key, "bool", cast.ToBoolE)
+}
+
+// GetIntStrict is GetInt, but returns an error as GetStringStrict does.
+func (w *Work) GetIntStrict(key string) (int, error) {
+	return strict(w, key, "int", cast.ToIntE)
+}
+
+// GetInt64Strict is GetInt64, but returns an error as GetStringStrict does.
+func (w *Work) GetInt64Strict(key string) (int64, error) {
+	return strict(w, key, "int64", cast.ToInt64E)
+}
+
+// GetFloat64Strict is GetFloat64, but returns an error as GetStringStrict does.
+func (w *Work) GetFloat64Strict(key string) (float64, error) {
+	return strict(w, key, "float64", cast.ToFloat64E)
+}
+
+// GetDurationStrict is GetDuration, but returns an error as GetStringStrict does.
+func (w *Work) GetDurationStrict(key string) (time.Duration, error) {
+	return strict(w, key, "time.Duration", cast.ToDurationE)
+}
+
+// GetTimeStrict is GetTime, but returns an error as GetStringStrict does.
+func (w *Work) GetTimeStrict(key string) (time.Time, error) {
+	return strict(w, key, "time.Time", cast.ToTimeE)
+}
+
+// masked returns a copy of the config, with the values of sensitive keys masked.
+func (w Work) masked() map[string]any {
+	sensitiveKeysLock.RLock()
+	defer sensitiveKeysLock.RUnlock()
+
+	m := make(map[string]any, len(w.config))
+	for k, v := range w.config {
+		m[k] = maskValue(k, v)
+	}
+	return m
+}
+
+// maskValue returns the value, or the mask if the key is sensitive. The caller must hold sensitiveKeysLock.
+func maskValue(key string, v any) any {
+	if _, ok := sensitiveKeys[key]; ok {
+		return sensitiveMask
+	}
+	return v
+}
+
+// String returns the Work as a string, with the values of keys marked via MarkSensitive masked.
+func (w Work) String() string {
+	return fmt.Sprint(w.masked())
+}
+
+// MarshalJSON returns the Work as a JSON object, with the values of keys marked via MarkSensitive masked, so
+// sensitive values don't survive a round-trip (see MarshalUnmasked). Work from a nil map is an empty object, {},
+// never null.
+func (w Work) MarshalJSON() ([]byte, error) {
+	return json.Marshal(w.masked())
+}
+
+// MarshalUnmasked is MarshalJSON, but with the values of sensitive keys kept, for persisting Work (e.g. to a
+// durable queue) that must round-trip through UnmarshalJSON intact. The JSON is as sensitive as the Work, so
+// mustn't be logged.
+func (w Work) MarshalUnmasked() ([]byte, error) {
+	if w.config == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(w.config)
+}
+
+// UnmarshalJSON replaces the Work's map with the JSON object, e.g. from MarshalUnmasked, or MarshalJSON if the
+// Work has no sensitive values. JSON numbers come back as float64s, which the getters cast as usual (GetInt of 3.0
+// is 3). A JSON null is an empty Work.
+func (w *Work) UnmarshalJSON(b []byte) error {
+	var config map[string]any
+	if err := json.Unmarshal(b, &config); err != nil {
+		return fmt.Errorf("racket: work is not a JSON object: %w", err)
+	}
+	w.config = config
+	return nil
+}
+
+// WorkDiff is the difference between two units of Work, by key. Values of keys marked via MarkSensitive are masked,
+// though a change to one is still reported.
+type WorkDiff struct {
+	// Added are the keys only in the new Work, with their values.
+	Added map[string]any `json:"added,omitempty"`
+	// Removed are the keys only in the old Work, with their old values.
+	Removed map[string]any `json:"removed,omitempty"`
+	// Changed are the keys in both, with different values, as [old, new].
+	Changed map[string][2]any `json:"changed,omitempty"`
+}
+
+// Empty returns true if there is no difference.
+func (d WorkDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff returns how the Work differs going to the other Work. The values are compared unmasked, and only masked
+// as they are reported.
+func (w Work) Diff(to Work) WorkDiff {
+	sensitiveKeysLock.RLock()
+	defer sensitiveKeysLock.RUnlock()
+
+	var d WorkDiff
+	for k, v := range to.config {
+		old, ok := w.config[k]
+		switch {
+		case !ok:
+			if d.Added == nil {
+				d.Added = make(map[string]any)
+			}
+			d.Added[k] = maskValue(k, v)
+		case !reflect.DeepEqual(old, v):
+			if d.Changed == nil {
+				d.Changed = make(map[string][2]any)
+			}
+			d.Changed[k] = [2]any{maskValue(k, old), maskValue(k, v)}
+		}
+	}
+	for k, v := range w.config {
+		if _, ok := to.config[k]; !ok {
+			if d.Removed == nil {
+				d.Removed = make(map[string]any)
+			}
+			d.Removed[k] = maskValue(k, v)
+		}
+	}
+	return d
 }
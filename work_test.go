@@ -1,7 +1,14 @@
 package racket
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -28,3 +35,582 @@ func Test_Work(t *testing.T) {
 
 	})
 }
+
+func Test_WorkNumbers(t *testing.T) {
+
+	Convey("When Work numbers are fetched as int64 and float64, values are casted gracefully", t, func() {
+		w := NewWork(map[string]any{
+			"Hello":      "World",
+			"Truth":      true,
+			"The Answer": 42,
+			"Big":        int64(1) << 40,
+			"Pi":         3.14,
+			"PiString":   "3.14",
+		})
+		empty := NewWork(nil)
+
+		tests := []struct {
+			name    string
+			work    Work
+			key     string
+			int64   int64
+			float64 float64
+		}{
+			{"an int", w, "The Answer", 42, 42},
+			{"a large int64", w, "Big", 1 << 40, 1 << 40},
+			{"a float", w, "Pi", 3, 3.14},
+			{"a numeric string", w, "PiString", 3, 3.14},
+			{"a bool", w, "Truth", 1, 1},
+			{"a non-numeric string", w, "Hello", 0, 0},
+			{"a missing key", w, "Does not exist", 0, 0},
+			{"a nil map", empty, "The Answer", 0, 0},
+		}
+		for _, test := range tests {
+			Convey("... "+test.name, func() {
+				So(test.work.GetInt64(test.key), ShouldEqual, test.int64)
+				So(test.work.GetFloat64(test.key), ShouldEqual, test.float64)
+			})
+		}
+	})
+}
+
+func Test_WorkDuration(t *testing.T) {
+
+	Convey("When Work durations are fetched, each representation is casted", t, func() {
+		w := NewWork(map[string]any{
+			"duration": 30 * time.Second,
+			"nanos":    int64(30 * time.Second),
+			"string":   "30s",
+			"garbage":  "thirty seconds",
+		})
+
+		So(w.GetDuration("duration"), ShouldEqual, 30*time.Second)
+		So(w.GetDuration("nanos"), ShouldEqual, 30*time.Second)
+		So(w.GetDuration("string"), ShouldEqual, 30*time.Second)
+
+		Convey("... and a malformed string, or a missing key, is zero", func() {
+			So(func() { w.GetDuration("garbage") }, ShouldNotPanic)
+			So(w.GetDuration("garbage"), ShouldBeZeroValue)
+			So(w.GetDuration("Does not exist"), ShouldBeZeroValue)
+		})
+	})
+}
+
+func Test_WorkTime(t *testing.T) {
+
+	Convey("When Work times are fetched, each representation is casted consistently", t, func() {
+		when := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		w := NewWork(map[string]any{
+			"time":    when,
+			"rfc3339": when.Format(time.RFC3339),
+			"epoch":   when.Unix(),
+			"garbage": "half past never",
+		})
+
+		So(w.GetTime("time").Equal(when), ShouldBeTrue)
+		So(w.GetTime("rfc3339").Equal(when), ShouldBeTrue)
+		So(w.GetTime("epoch").Equal(when), ShouldBeTrue)
+
+		Convey("... and a garbage string, or a missing key, is the zero time", func() {
+			So(w.GetTime("garbage").IsZero(), ShouldBeTrue)
+			So(w.GetTime("Does not exist").IsZero(), ShouldBeTrue)
+		})
+	})
+}
+
+func Test_WorkHas(t *testing.T) {
+
+	Convey("When Work is asked if it Has a key, missing keys are told apart from zero values", t, func() {
+		w := NewWork(map[string]any{
+			"retries": 0,
+			"nothing": nil,
+		})
+
+		So(w.Has("retries"), ShouldBeTrue)
+		So(w.Has("nothing"), ShouldBeTrue)
+		So(w.Has("Does not exist"), ShouldBeFalse)
+
+		Convey("... and Work from a nil map has nothing", func() {
+			e := NewWork(nil)
+			So(e.Has("retries"), ShouldBeFalse)
+		})
+	})
+}
+
+func Test_WorkSetDelete(t *testing.T) {
+
+	Convey("When Work from a nil map is Set, the values are retrievable", t, func() {
+		w := NewWork(nil)
+		w.Set("x", 1)
+		w.Set("name", "bob")
+
+		So(w.GetInt("x"), ShouldEqual, 1)
+		So(w.GetString("name"), ShouldEqual, "bob")
+
+		Convey("... and Set overwrites, while Delete removes, and is a no-op for missing keys", func() {
+			w.Set("x", 2)
+			So(w.GetInt("x"), ShouldEqual, 2)
+
+			w.Delete("x")
+			So(w.Has("x"), ShouldBeFalse)
+			So(func() { w.Delete("Does not exist") }, ShouldNotPanic)
+			So(w.Has("name"), ShouldBeTrue)
+		})
+	})
+
+	Convey("When Work from a nil map is Deleted from, nothing panics", t, func() {
+		w := NewWork(nil)
+		So(func() { w.Delete("x") }, ShouldNotPanic)
+	})
+}
+
+func Test_NewWorkFromStruct(t *testing.T) {
+
+	type spec struct {
+		Name    string `racket:"name"`
+		Retries int
+		Wait    time.Duration `racket:"wait"`
+		Skipped string        `racket:"-"`
+		secret  string
+	}
+
+	Convey("When Work is made from a struct, exported fields are keyed by name or tag", t, func() {
+		w, err := NewWorkFromStruct(spec{Name: "bob", Retries: 3, Wait: time.Second, Skipped: "no", secret: "shh"})
+		So(err, ShouldBeNil)
+		So(w.Keys(), ShouldResemble, []string{"Retries", "name", "wait"})
+		So(w.GetString("name"), ShouldEqual, "bob")
+		So(w.GetInt("Retries"), ShouldEqual, 3)
+		So(w.GetDuration("wait"), ShouldEqual, time.Second)
+
+		Convey("... and from a pointer to a struct", func() {
+			p, err := NewWorkFromStruct(&spec{Name: "alice"})
+			So(err, ShouldBeNil)
+			So(p.GetString("name"), ShouldEqual, "alice")
+		})
+	})
+
+	Convey("When Work is made from something that isn't a struct, it is an error", t, func() {
+		_, err := NewWorkFromStruct(42)
+		So(err, ShouldBeError, "racket: NewWorkFromStruct called with a int, not a struct")
+
+		_, err = NewWorkFromStruct((*spec)(nil))
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func Test_WorkMerge(t *testing.T) {
+
+	Convey("When Work is Merged, the other's keys win, and neither is modified", t, func() {
+		base := NewWork(map[string]any{"region": "us-east", "retries": 3})
+		item := NewWork(map[string]any{"retries": 5, "id": 1})
+
+		m := base.Merge(item)
+		So(m.Keys(), ShouldResemble, []string{"id", "region", "retries"})
+		So(m.GetInt("retries"), ShouldEqual, 5)
+		So(m.GetString("region"), ShouldEqual, "us-east")
+
+		So(base.GetInt("retries"), ShouldEqual, 3)
+		So(base.Has("id"), ShouldBeFalse)
+		So(item.Has("region"), ShouldBeFalse)
+
+		Convey("... and disjoint keys are all kept", func() {
+			d := NewWork(map[string]any{"a": 1}).Merge(NewWork(map[string]any{"b": 2}))
+			So(d.Keys(), ShouldResemble, []string{"a", "b"})
+		})
+
+		Convey("... and Work from nil maps merge gracefully", func() {
+			into := NewWork(nil).Merge(item)
+			So(into.Keys(), ShouldResemble, []string{"id", "retries"})
+
+			from := base.Merge(NewWork(nil))
+			So(from.Keys(), ShouldResemble, []string{"region", "retries"})
+
+			none := NewWork(nil).Merge(NewWork(nil))
+			So(none.Len(), ShouldEqual, 0)
+		})
+	})
+}
+
+func Test_WorkClone(t *testing.T) {
+
+	Convey("When a Work is Cloned, mutating the clone leaves the original unchanged", t, func() {
+		template := NewWork(map[string]any{"region": "us-east", "id": 0})
+
+		c := template.Clone()
+		c.Set("id", 7)
+		c.Set("extra", true)
+		c.Delete("region")
+
+		So(template.Keys(), ShouldResemble, []string{"id", "region"})
+		So(template.GetInt("id"), ShouldEqual, 0)
+		So(template.GetString("region"), ShouldEqual, "us-east")
+		So(c.Keys(), ShouldResemble, []string{"extra", "id"})
+		So(c.GetInt("id"), ShouldEqual, 7)
+
+		Convey("... even while the template is read concurrently", func() {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for range 100 {
+					_ = template.GetString("region")
+				}
+			}()
+			for i := range 100 {
+				w := template.Clone()
+				w.Set("id", i)
+			}
+			<-done
+		})
+
+		Convey("... and a nil-backed Work clones to an empty one", func() {
+			e := NewWork(nil).Clone()
+			e.Set("x", 1)
+			So(e.GetInt("x"), ShouldEqual, 1)
+		})
+	})
+}
+
+func Test_WorkRequire(t *testing.T) {
+
+	Convey("When Work is Required to have keys, all of the missing keys are named", t, func() {
+		w := NewWork(map[string]any{"b": 2, "nothing": nil})
+
+		So(w.Require("b", "nothing"), ShouldBeNil)
+		So(w.Require(), ShouldBeNil)
+
+		err := w.Require("a", "b", "c")
+		So(err, ShouldBeError, "racket: missing required work keys: [a, c]")
+		So(errors.Is(err, ErrMissingKey), ShouldBeTrue)
+
+		Convey("... and Work from a nil map is missing them all", func() {
+			e := NewWork(nil)
+			So(e.Require("a", "b"), ShouldBeError, "racket: missing required work keys: [a, b]")
+		})
+	})
+}
+
+func Test_WorkKeys(t *testing.T) {
+
+	Convey("When Work is asked for its Keys and Len, they are sorted and counted", t, func() {
+		w := NewWork(map[string]any{
+			"zebra":    1,
+			"apple":    nil,
+			"mushroom": "yes",
+		})
+
+		So(w.Keys(), ShouldResemble, []string{"apple", "mushroom", "zebra"})
+		So(w.Len(), ShouldEqual, 3)
+
+		Convey("... and Work from a nil map has none", func() {
+			e := NewWork(nil)
+			So(e.Keys(), ShouldNotBeNil)
+			So(e.Keys(), ShouldBeEmpty)
+			So(e.Len(), ShouldEqual, 0)
+		})
+	})
+}
+
+func Test_WorkGetAs(t *testing.T) {
+
+	type config struct {
+		Region  string
+		Retries int
+	}
+
+	Convey("When Work values are gotten as a type, they are type asserted", t, func() {
+		w := NewWork(map[string]any{
+			"cfg":  config{Region: "us-east", Retries: 3},
+			"pcfg": &config{Region: "eu-west"},
+			"n":    "42",
+		})
+
+		cfg, ok := GetAs[config](w, "cfg")
+		So(ok, ShouldBeTrue)
+		So(cfg, ShouldResemble, config{Region: "us-east", Retries: 3})
+
+		pcfg, ok := GetAs[*config](w, "pcfg")
+		So(ok, ShouldBeTrue)
+		So(pcfg.Region, ShouldEqual, "eu-west")
+
+		Convey("... and mismatched types aren't cast, but return false", func() {
+			n, ok := GetAs[int](w, "n")
+			So(ok, ShouldBeFalse)
+			So(n, ShouldEqual, 0)
+
+			_, ok = GetAs[*config](w, "cfg")
+			So(ok, ShouldBeFalse)
+		})
+
+		Convey("... and missing keys return false", func() {
+			missing, ok := GetAs[config](w, "Does not exist")
+			So(ok, ShouldBeFalse)
+			So(missing, ShouldResemble, config{})
+		})
+	})
+}
+
+func Test_WorkIntE(t *testing.T) {
+
+	Convey("When Work ints are fetched with IntE, bad input is an error rather than zero", t, func() {
+		w := NewWork(map[string]any{
+			"int":    42,
+			"string": "42",
+			"bad":    "forty-two",
+		})
+
+		Convey("... a valid number is returned without error", func() {
+			i, err := w.IntE("int")
+			So(err, ShouldBeNil)
+			So(i, ShouldEqual, 42)
+
+			i, err = w.IntE("string")
+			So(err, ShouldBeNil)
+			So(i, ShouldEqual, 42)
+		})
+
+		Convey("... a non-numeric string is an error, the same as GetIntStrict's", func() {
+			i, err := w.IntE("bad")
+			So(err, ShouldBeError, `racket: work key "bad": expected int-convertible, got string`)
+			So(errors.Is(err, ErrMissingKey), ShouldBeFalse)
+			So(i, ShouldEqual, 0)
+
+			_, strictErr := w.GetIntStrict("bad")
+			So(err, ShouldResemble, strictErr)
+		})
+
+		Convey("... an absent key is an ErrMissingKey error", func() {
+			i, err := w.IntE("absent")
+			So(errors.Is(err, ErrMissingKey), ShouldBeTrue)
+			So(i, ShouldEqual, 0)
+		})
+	})
+}
+
+func Test_WorkStrict(t *testing.T) {
+
+	Convey("When Work is asked for values strictly, convertible values are returned", t, func() {
+		w := NewWork(map[string]any{
+			"n":     "42",
+			"names": []string{"a", "b"},
+			"ok":    "true",
+			"wait":  "30s",
+			"when":  "2024-01-02T03:04:05Z",
+		})
+
+		n, err := w.GetIntStrict("n")
+		So(err, ShouldBeNil)
+		So(n, ShouldEqual, 42)
+
+		ok, err := w.GetBoolStrict("ok")
+		So(err, ShouldBeNil)
+		So(ok, ShouldBeTrue)
+
+		wait, err := w.GetDurationStrict("wait")
+		So(err, ShouldBeNil)
+		So(wait, ShouldEqual, 30*time.Second)
+
+		when, err := w.GetTimeStrict("when")
+		So(err, ShouldBeNil)
+		So(when.Year(), ShouldEqual, 2024)
+
+		Convey("... while unconvertible values describe what was stored", func() {
+			n, err := w.GetIntStrict("names")
+			So(err, ShouldBeError, `racket: work key "names": expected int-convertible, got []string`)
+			So(n, ShouldEqual, 0)
+
+			_, err = w.GetFloat64Strict("names")
+			So(err, ShouldBeError, `racket: work key "names": expected float64-convertible, got []string`)
+
+			_, err = w.GetStringStrict("names")
+			So(err, ShouldBeError, `racket: work key "names": expected string-convertible, got []string`)
+		})
+
+		Convey("... and missing keys are ErrMissingKey", func() {
+			_, err := w.GetInt64Strict("Does not exist")
+			So(errors.Is(err, ErrMissingKey), ShouldBeTrue)
+		})
+	})
+}
+
+func Test_WorkSensitive(t *testing.T) {
+
+	Convey("When Work keys are marked sensitive, their values are masked in String and JSON, but still readable.", t, func() {
+		MarkSensitive("password", "token")
+		defer unmarkSensitive("password", "token")
+		w := NewWork(map[string]any{
+			"user":     "bob",
+			"password": "hunter2",
+			"token":    "s3cr3t",
+		})
+
+		So(w.String(), ShouldEqual, "map[password:*** token:*** user:bob]")
+		So(fmt.Sprint(w), ShouldNotContainSubstring, "hunter2")
+
+		b, err := json.Marshal(w)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{"password":"***","token":"***","user":"bob"}`)
+
+		So(w.GetString("password"), ShouldEqual, "hunter2")
+		So(w.GetString("token"), ShouldEqual, "s3cr3t")
+
+		Convey("... and empty Work is an empty object", func() {
+			b, err := json.Marshal(NewWork(nil))
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, "{}")
+		})
+
+		Convey("... unless marshaled unmasked, to persist the Work, which round-trips the values", func() {
+			b, err := w.MarshalUnmasked()
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, `{"password":"hunter2","token":"s3cr3t","user":"bob"}`)
+
+			var back Work
+			So(json.Unmarshal(b, &back), ShouldBeNil)
+			So(back.GetString("password"), ShouldEqual, "hunter2")
+			So(back.String(), ShouldEqual, w.String())
+
+			b, err = NewWork(nil).MarshalUnmasked()
+			So(err, ShouldBeNil)
+			So(string(b), ShouldEqual, "{}")
+		})
+	})
+
+	Convey("When the test is done with them, the keys aren't left marked sensitive for the rest.", t, func() {
+		So(NewWork(map[string]any{"password": "hunter2"}).String(), ShouldEqual, "map[password:hunter2]")
+	})
+}
+
+func Test_WorkJSON(t *testing.T) {
+
+	Convey("When Work is marshaled to JSON, it is the map", t, func() {
+		w := NewWork(map[string]any{
+			"name":  "bob",
+			"count": 3,
+			"ratio": 0.5,
+			"tags":  []string{"a", "b"},
+			"wait":  "30s",
+		})
+
+		b, err := json.Marshal(w)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, `{"count":3,"name":"bob","ratio":0.5,"tags":["a","b"],"wait":"30s"}`)
+
+		Convey("... and unmarshaling it into a zero Work round-trips the values through the getters", func() {
+			var back Work
+			So(json.Unmarshal(b, &back), ShouldBeNil)
+
+			So(back.Keys(), ShouldResemble, w.Keys())
+			So(back.Get("count"), ShouldHaveSameTypeAs, float64(0))
+			So(back.GetInt("count"), ShouldEqual, w.GetInt("count"))
+			So(back.GetFloat64("ratio"), ShouldEqual, w.GetFloat64("ratio"))
+			So(back.GetString("name"), ShouldEqual, w.GetString("name"))
+			So(back.GetStringSlice("tags"), ShouldResemble, w.GetStringSlice("tags"))
+			So(back.GetDuration("wait"), ShouldEqual, w.GetDuration("wait"))
+		})
+	})
+
+	Convey("When JSON null is unmarshaled into Work, it is empty, and marshals as {}", t, func() {
+		var w Work
+		So(json.Unmarshal([]byte("null"), &w), ShouldBeNil)
+		So(w.Len(), ShouldEqual, 0)
+
+		b, err := json.Marshal(w)
+		So(err, ShouldBeNil)
+		So(string(b), ShouldEqual, "{}")
+	})
+
+	Convey("When JSON that isn't an object is unmarshaled into Work, it is an error", t, func() {
+		var w Work
+		So(json.Unmarshal([]byte("[1,2]"), &w), ShouldNotBeNil)
+	})
+}
+
+func Test_WorkDiff(t *testing.T) {
+
+	Convey("When Work is diffed, additions, removals, and changes are reported by key.", t, func() {
+		MarkSensitive("password")
+		defer unmarkSensitive("password")
+		from := NewWork(map[string]any{
+			"same":     1,
+			"changed":  "old",
+			"removed":  true,
+			"password": "hunter2",
+		})
+		to := NewWork(map[string]any{
+			"same":     1,
+			"changed":  "new",
+			"added":    []string{"a"},
+			"password": "hunter3",
+		})
+
+		d := from.Diff(to)
+		So(d.Empty(), ShouldBeFalse)
+		So(d.Added, ShouldResemble, map[string]any{"added": []string{"a"}})
+		So(d.Removed, ShouldResemble, map[string]any{"removed": true})
+		So(d.Changed, ShouldResemble, map[string][2]any{"changed": {"old", "new"}, "password": {"***", "***"}})
+
+		Convey("... sensitive changes are reported, but their values aren't revealed", func() {
+			So(d.Changed["password"], ShouldResemble, [2]any{"***", "***"})
+			So(from.Diff(NewWork(nil)).Removed["password"], ShouldEqual, "***")
+			So(NewWork(nil).Diff(to).Added["password"], ShouldEqual, "***")
+		})
+
+		Convey("... and identical Work has an empty diff", func() {
+			So(from.Diff(from).Empty(), ShouldBeTrue)
+		})
+	})
+}
+
+func Test_WorkFromValues(t *testing.T) {
+
+	Convey("When Work is created from url.Values, values are flattened as expected", t, func() {
+		v := url.Values{}
+		v.Set("single", "hello world")
+		v.Add("multi", "one")
+		v.Add("multi", "two")
+
+		w := WorkFromValues(v)
+
+		Convey("... a single-valued key is a scalar string, and a one-element slice", func() {
+			So(w.Get("single"), ShouldHaveSameTypeAs, "")
+			So(w.GetString("single"), ShouldEqual, "hello world")
+			So(w.GetStringSlice("single"), ShouldResemble, []string{"hello world"})
+		})
+
+		Convey("... a multi-valued key is a slice", func() {
+			So(w.Get("multi"), ShouldHaveSameTypeAs, []string{})
+			So(w.GetStringSlice("multi"), ShouldResemble, []string{"one", "two"})
+		})
+
+		Convey("... an empty url.Values is empty Work", func() {
+			e := WorkFromValues(url.Values{})
+			So(e.Get("single"), ShouldBeNil)
+			So(e.GetString("single"), ShouldBeEmpty)
+			So(e.GetStringSlice("single"), ShouldBeEmpty)
+		})
+	})
+}
+
+func Test_WorkDebug(t *testing.T) {
+
+	Convey("When Work debugging is toggled, missing keys are warned about only while it is on", t, func() {
+		var out bytes.Buffer
+		w := NewWork(map[string]any{
+			"host": "localhost",
+		})
+
+		SetWorkDebug(log.New(&out, "", 0))
+		defer SetWorkDebug(nil)
+
+		So(w.GetString("host"), ShouldEqual, "localhost")
+		So(out.String(), ShouldBeEmpty)
+
+		So(w.GetStringSlice("hosts"), ShouldBeEmpty)
+		So(out.String(), ShouldEqual, "[RACKET] WARNING: work key \"hosts\" does not exist\n")
+
+		out.Reset()
+		SetWorkDebug(nil)
+		So(w.GetInt("port"), ShouldEqual, 0)
+		So(out.String(), ShouldBeEmpty)
+	})
+}
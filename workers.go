@@ -0,0 +1,131 @@
+package racket
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// CopyWorker returns a WorkerFunc that copies from the io.Reader in the srcKey of the Work, to the io.Writer in the
+// dstKey of the Work, sending a PBytes update as each chunk is written. Missing or mistyped keys, and copy errors,
+// are sent as a ProgressError. Closing the reader or writer, if needed, is left to the caller.
+func CopyWorker(srcKey, dstKey string) WorkerFunc {
+	return func(id any, work Work, progressChan chan<- Progress) {
+		src, ok := work.Get(srcKey).(io.Reader)
+		if !ok {
+			progressChan <- PErrorf("worker %v: work key %q is not an io.Reader", id, srcKey)
+			return
+		}
+		dst, ok := work.Get(dstKey).(io.Writer)
+		if !ok {
+			progressChan <- PErrorf("worker %v: work key %q is not an io.Writer", id, dstKey)
+			return
+		}
+
+		if _, err := io.Copy(&progressWriter{w: dst, progressChan: progressChan}, src); err != nil {
+			progressChan <- PErrorf("worker %v: copy failed: %w", id, err)
+		}
+	}
+}
+
+// progressWriter is an io.Writer that sends a PBytes update for every successful Write.
+type progressWriter struct {
+	w            io.Writer
+	progressChan chan<- Progress
+}
+
+// Write writes to the underlying io.Writer, and sends a PBytes update for the bytes written.
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	if n > 0 {
+		p.progressChan <- PBytes(int64(n))
+	}
+	return n, err
+}
+
+// execWaitDelay is how long an ExecWorker waits for a command's output to close after the command exits, or is
+// killed, e.g. if a child of its own still holds it open, before giving up on it.
+var execWaitDelay = 5 * time.Second
+
+// ExecWorker returns a WorkerFuncValue that runs the command in the cmdKey of the Work, with the arguments in the
+// argsKey (see GetStringSlice), sending each line of its stdout and stderr as a ProgressMessage as it's written.
+// Lines may be any length. The value is the combined output. The command is killed if the context is done, so
+// WithItemTimeout bounds each command. A missing command, or a command that fails to start, exits non-zero, or
+// leaves its output open for long after it exits, is returned as an error.
+func ExecWorker(cmdKey, argsKey string) WorkerFuncValue {
+	return func(ctx context.Context, id any, work Work, progressChan chan<- Progress) (any, error) {
+		name := work.GetString(cmdKey)
+		if name == "" {
+			return nil, fmt.Errorf("worker %v: work key %q has no command", id, cmdKey)
+		}
+
+		var (
+			output     bytes.Buffer
+			outputLock sync.Mutex
+			stdout     = &lineWriter{output: &output, outputLock: &outputLock, progressChan: progressChan}
+			stderr     = &lineWriter{output: &output, outputLock: &outputLock, progressChan: progressChan}
+		)
+		cmd := exec.CommandContext(ctx, name, work.GetStringSlice(argsKey)...)
+		// exec copies the output to the writers, and Wait waits for that, but only up to the WaitDelay.
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		cmd.WaitDelay = execWaitDelay
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("worker %v: command %q failed to start: %w", id, name, err)
+		}
+
+		err := cmd.Wait()
+		stdout.flush()
+		stderr.flush()
+		if err != nil {
+			return output.String(), fmt.Errorf("worker %v: command %q failed: %w", id, name, err)
+		}
+		return output.String(), nil
+	}
+}
+
+// lineWriter is an io.Writer that splits what is written into lines, appending each to the shared output, and
+// sending it as a ProgressMessage. A final line without a newline is held until flush.
+type lineWriter struct {
+	output       *bytes.Buffer
+	outputLock   *sync.Mutex
+	progressChan chan<- Progress
+	partial      []byte
+}
+
+// Write handles every complete line written so far, and holds on to the rest.
+func (l *lineWriter) Write(b []byte) (int, error) {
+	l.partial = append(l.partial, b...)
+	rest := l.partial
+	for {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			break
+		}
+		l.line(bytes.TrimSuffix(rest[:i], []byte("\r")))
+		rest = rest[i+1:]
+	}
+	l.partial = append(l.partial[:0], rest...)
+	return len(b), nil
+}
+
+// flush handles the final line, if it had no newline.
+func (l *lineWriter) flush() {
+	if len(l.partial) > 0 {
+		l.line(l.partial)
+		l.partial = nil
+	}
+}
+
+// line appends the line to the output, and sends it as a ProgressMessage.
+func (l *lineWriter) line(b []byte) {
+	line := string(b)
+	l.outputLock.Lock()
+	l.output.WriteString(line + "\n")
+	l.outputLock.Unlock()
+	l.progressChan <- Progress{Type: ProgressMessage, Data: line}
+}
@@ -0,0 +1,132 @@
+package racket
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func Test_CopyWorker(t *testing.T) {
+
+	Convey("When a CopyWorker copies from a reader to a writer, bytes are reported and copied", t, func() {
+		var (
+			dst      bytes.Buffer
+			progress []Progress
+			content  = strings.Repeat("racket!", 10000)
+		)
+
+		j := NewInlineJob(CopyWorker("src", "dst"), func(p Progress) {
+			progress = append(progress, p)
+		})
+		j.Add(NewWork(map[string]any{
+			"src": strings.NewReader(content),
+			"dst": &dst,
+		}))
+
+		So(dst.String(), ShouldEqual, content)
+
+		var total int64
+		for _, p := range progress {
+			So(p.Type, ShouldEqual, ProgressUpdate)
+			total += p.Data.(int64)
+		}
+		So(total, ShouldEqual, len(content))
+	})
+
+	Convey("When a CopyWorker has bad Work, errors are reported", t, func() {
+		var progress []Progress
+
+		j := NewInlineJob(CopyWorker("src", "dst"), func(p Progress) {
+			progress = append(progress, p)
+		})
+		j.Add(NewWork(map[string]any{"dst": &bytes.Buffer{}}))
+		j.Add(NewWork(map[string]any{"src": strings.NewReader("hello")}))
+
+		So(progress, ShouldHaveLength, 2)
+		So(progress[0].Error().Error(), ShouldEqual, "worker 0: work key \"src\" is not an io.Reader")
+		So(progress[1].Error().Error(), ShouldEqual, "worker 1: work key \"dst\" is not an io.Writer")
+	})
+}
+
+func Test_ExecWorker(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("no echo command")
+	}
+
+	Convey("When an ExecWorker runs commands, their output is reported as progress, and in the Result", t, func() {
+		var (
+			messages []string
+			errs     []error
+		)
+		rs := collectResultsProgress(func(results chan<- Result) Job {
+			return NewJobValue(ExecWorker("cmd", "args"), WithResults(results))
+		}, []Work{
+			NewWork(map[string]any{"cmd": "echo", "args": []string{"hello", "racket"}}),
+			NewWork(map[string]any{"cmd": "sh", "args": []string{"-c", "echo oops; exit 3"}}),
+		}, nil, func(p Progress) {
+			switch p.Type {
+			case ProgressMessage:
+				messages = append(messages, p.Data.(string))
+			case ProgressError:
+				errs = append(errs, p.Error())
+			}
+		})
+
+		So(messages, ShouldContain, "hello racket")
+		So(messages, ShouldContain, "oops")
+		So(errs, ShouldHaveLength, 1)
+		So(errs[0].Error(), ShouldContainSubstring, "exit status 3")
+
+		So(rs, ShouldHaveLength, 2)
+		for _, r := range rs {
+			switch r.Work.GetString("cmd") {
+			case "echo":
+				So(r.Status, ShouldEqual, ResultSuccess)
+				So(r.Value, ShouldEqual, "hello racket\n")
+			default:
+				So(r.Status, ShouldEqual, ResultFailed)
+				So(r.Value, ShouldEqual, "oops\n")
+			}
+		}
+	})
+
+	Convey("When an ExecWorker's command writes a very long line, or no final newline, it is all reported", t, func() {
+		var messages []string
+		rs := collectResultsProgress(func(results chan<- Result) Job {
+			return NewJobValue(ExecWorker("cmd", "args"), WithResults(results))
+		}, []Work{
+			NewWork(map[string]any{"cmd": "sh", "args": []string{"-c", "head -c 300000 /dev/zero | tr '\\0' a; echo; printf 'no newline' >&2"}}),
+		}, nil, func(p Progress) {
+			if p.Type == ProgressMessage {
+				messages = append(messages, p.Data.(string))
+			}
+		})
+
+		So(rs, ShouldHaveLength, 1)
+		So(rs[0].Status, ShouldEqual, ResultSuccess)
+		So(messages, ShouldHaveLength, 2)
+		So(messages, ShouldContain, strings.Repeat("a", 300000))
+		So(messages, ShouldContain, "no newline")
+		So(rs[0].Value.(string), ShouldHaveLength, 300000+len("no newline")+2)
+	})
+
+	Convey("When an ExecWorker's command exits with its output held open, it gives up after the wait delay", t, func() {
+		defer func(delay time.Duration) { execWaitDelay = delay }(execWaitDelay)
+		execWaitDelay = 50 * time.Millisecond
+
+		start := time.Now()
+		rs := collectResults(func(results chan<- Result) Job {
+			return NewJobValue(ExecWorker("cmd", "args"), WithResults(results))
+		}, []Work{
+			NewWork(map[string]any{"cmd": "sh", "args": []string{"-c", "sleep 2 & echo started"}}),
+		}, nil)
+
+		So(time.Since(start), ShouldBeLessThan, 2*time.Second)
+		So(rs, ShouldHaveLength, 1)
+		So(rs[0].Err, ShouldWrap, exec.ErrWaitDelay)
+	})
+}